@@ -5,6 +5,7 @@ go 1.18
 require (
 	github.com/google/go-cmp v0.5.8
 	github.com/spf13/cobra v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
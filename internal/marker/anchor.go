@@ -0,0 +1,195 @@
+package marker
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Anchor holds regular expression based line range bound. Unlike line
+// numbers, the actual line number can only be found with the import target
+// file content.
+type Anchor struct {
+	Pattern string
+
+	// Exclusive removes the matched line itself from the line range.
+	Exclusive bool
+}
+
+var (
+	errInvalidAnchor = errors.New("invalid anchor in line range")
+)
+
+// splitTargetDetail splits the input by sep, while keeping anchor pattern
+// (e.g. "/some~pattern/") and exporter marker (e.g. "[some,marker]") as is.
+func splitTargetDetail(input string, sep byte) []string {
+	result := []string{}
+
+	inPattern := false
+	inBracket := false
+	start := 0
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; {
+		case inPattern && c == '\\':
+			i++ // Skip escaped character
+		case c == '/' && !inBracket:
+			inPattern = !inPattern
+		case c == '[' && !inPattern:
+			inBracket = true
+		case c == ']' && !inPattern:
+			inBracket = false
+		case c == sep && !inPattern && !inBracket:
+			result = append(result, input[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, input[start:])
+}
+
+// isAnchorRange checks whether the input is a line range using any anchor.
+func isAnchorRange(input string) bool {
+	bounds := splitTargetDetail(input, '~')
+	if len(bounds) != 2 {
+		return false
+	}
+	return strings.HasPrefix(bounds[0], "/") || strings.HasPrefix(bounds[1], "/")
+}
+
+// getLineRangeWithAnchor parses line range where either or both of the bounds
+// are specified with anchors, e.g. "/^func main/~/^}/".
+func getLineRangeWithAnchor(input string) (ImportLogic, error) {
+	logic := ImportLogic{
+		Type:     LineRange,
+		LineFrom: 0,
+		LineTo:   math.MaxInt32,
+	}
+
+	bounds := splitTargetDetail(input, '~')
+	if len(bounds) != 2 {
+		return logic, fmt.Errorf("%w", errMultipleTildes)
+	}
+
+	lb, ub := bounds[0], bounds[1]
+
+	switch {
+	case strings.HasPrefix(lb, "/"):
+		a, err := parseAnchor(lb)
+		if err != nil {
+			return logic, err
+		}
+		logic.LineFromAnchor = a
+	case lb != "":
+		l, err := strconv.Atoi(lb)
+		if err != nil {
+			return logic, fmt.Errorf("%w, %v", errLowerBound, err)
+		}
+		logic.LineFrom = l
+	}
+
+	switch {
+	case strings.HasPrefix(ub, "/"):
+		a, err := parseAnchor(ub)
+		if err != nil {
+			return logic, err
+		}
+		logic.LineToAnchor = a
+	case ub != "":
+		u, err := strconv.Atoi(ub)
+		if err != nil {
+			return logic, fmt.Errorf("%w, %v", errUpperBound, err)
+		}
+		logic.LineTo = u
+	}
+
+	return logic, nil
+}
+
+// parseAnchor parses a single anchor input such as "/^func main/". Adding "x"
+// after the closing slash, e.g. "/^func main/x", makes the anchor exclusive.
+func parseAnchor(input string) (*Anchor, error) {
+	end := strings.LastIndex(input, "/")
+	if end < 1 {
+		return nil, fmt.Errorf("%w, missing closing slash in '%s'", errInvalidAnchor, input)
+	}
+
+	a := &Anchor{Pattern: input[1:end]}
+	switch flag := input[end+1:]; flag {
+	case "":
+	case "x":
+		a.Exclusive = true
+	default:
+		return nil, fmt.Errorf("%w, unknown flag '%s'", errInvalidAnchor, flag)
+	}
+
+	if a.Pattern == "" {
+		return nil, fmt.Errorf("%w, empty pattern", errInvalidAnchor)
+	}
+	if _, err := regexp.Compile(a.Pattern); err != nil {
+		return nil, fmt.Errorf("%w, %v", errInvalidAnchor, err)
+	}
+
+	return a, nil
+}
+
+// resolveAnchors finds the lines matching anchors, and returns the line
+// range. The upper bound anchor is searched from the line after the lower
+// bound.
+func resolveAnchors(content []byte, logic ImportLogic) (int, int, error) {
+	from, to := logic.LineFrom, logic.LineTo
+	searchFrom := from + 1
+
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if a := logic.LineFromAnchor; a != nil {
+		l, err := findAnchor(lines, a, 1)
+		if err != nil {
+			return 0, 0, err
+		}
+		from = l
+		searchFrom = l + 1
+		if a.Exclusive {
+			from = l + 1
+		}
+	}
+
+	if a := logic.LineToAnchor; a != nil {
+		l, err := findAnchor(lines, a, searchFrom)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = l
+		if a.Exclusive {
+			to = l - 1
+		}
+	}
+
+	return from, to, nil
+}
+
+// findAnchor returns the first line number matching the anchor, starting the
+// search at line number `start`.
+func findAnchor(lines []string, a *Anchor, start int) (int, error) {
+	re, err := regexp.Compile(a.Pattern)
+	if err != nil {
+		return 0, fmt.Errorf("%w, %v", errInvalidAnchor, err)
+	}
+
+	if start < 1 {
+		start = 1
+	}
+	for i := start; i <= len(lines); i++ {
+		if re.MatchString(lines[i-1]) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w for '/%s/'", ErrNoMatchingAnchor, a.Pattern)
+}
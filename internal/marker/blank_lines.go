@@ -0,0 +1,63 @@
+package marker
+
+import "strings"
+
+func isBlankLine(l line) bool {
+	return strings.TrimSpace(l.text) == ""
+}
+
+// trimBlankLines removes the leading and trailing blank lines from the
+// imported content. Sections are treated as a single block, and thus only the
+// blank lines at the beginning of the first section and the end of the last
+// section are removed.
+func trimBlankLines(sections []section) []section {
+	first, last := -1, -1
+	index := 0
+	for _, s := range sections {
+		for _, l := range s.lines {
+			if !isBlankLine(l) {
+				if first < 0 {
+					first = index
+				}
+				last = index
+			}
+			index++
+		}
+	}
+
+	result := make([]section, 0, len(sections))
+	index = 0
+	for _, s := range sections {
+		lines := []line{}
+		for _, l := range s.lines {
+			if index >= first && index <= last {
+				lines = append(lines, l)
+			}
+			index++
+		}
+		s.lines = lines
+		result = append(result, s)
+	}
+	return result
+}
+
+// squeezeBlankLines collapses consecutive blank lines into a single blank
+// line, including the ones spanning across sections.
+func squeezeBlankLines(sections []section) []section {
+	result := make([]section, 0, len(sections))
+	prevBlank := false
+	for _, s := range sections {
+		lines := []line{}
+		for _, l := range s.lines {
+			blank := isBlankLine(l)
+			if blank && prevBlank {
+				continue
+			}
+			prevBlank = blank
+			lines = append(lines, l)
+		}
+		s.lines = lines
+		result = append(result, s)
+	}
+	return result
+}
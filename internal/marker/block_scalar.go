@@ -0,0 +1,47 @@
+package marker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// blockScalarIndent is the indentation of the block scalar content, relative
+// to the key.
+const blockScalarIndent = 2
+
+// render returns the lines as YAML literal block scalar. The common
+// indentation of the lines is removed, and the lines are indented under the
+// key. When the first line has leading whitespace, indentation indicator is
+// added so that the whitespace is kept as content.
+func (b *BlockScalar) render(sections []section) []byte {
+	sections = dedentSections(sections, 0, ' ')
+
+	indicator := "|"
+	if first := firstNonEmptyLine(sections); strings.HasPrefix(first, " ") || strings.HasPrefix(first, "\t") {
+		indicator += strconv.Itoa(blockScalarIndent)
+	}
+
+	indent := strings.Repeat(" ", b.Indentation+blockScalarIndent)
+	result := []byte(strings.Repeat(" ", b.Indentation) + b.Key + ": " + indicator)
+	result = append(result, br)
+	for _, s := range sections {
+		for _, l := range s.lines {
+			if l.text != "" {
+				result = append(result, []byte(indent+l.text)...)
+			}
+			result = append(result, br)
+		}
+	}
+	return result
+}
+
+func firstNonEmptyLine(sections []section) string {
+	for _, s := range sections {
+		for _, l := range s.lines {
+			if l.text != "" {
+				return l.text
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package marker
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// captionSource returns the label and link pointing to the imported lines.
+// For local files, the link is relative to the importing file. For URLs
+// pointing to GitHub, the link is converted to the GitHub file view with line
+// highlight.
+func (m *Marker) captionSource(sections []section) (string, string) {
+	target := filepath.ToSlash(m.ImportTargetFile.File)
+	name := path.Base(target)
+	link := target
+
+	if m.ImportTargetFile.Type == URLBased {
+		u, err := url.Parse(target)
+		if err == nil {
+			name = path.Base(u.Path)
+			u.RawQuery = ""
+			u.Fragment = ""
+			link = u.String()
+
+			// "raw.githubusercontent.com/ORG/REPO/REF/PATH" is viewed as
+			// "github.com/ORG/REPO/blob/REF/PATH".
+			if u.Host == "raw.githubusercontent.com" {
+				if ps := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 3); len(ps) == 3 {
+					u.Host = "github.com"
+					u.Path = "/" + ps[0] + "/" + ps[1] + "/blob/" + ps[2]
+					link = u.String()
+				}
+			}
+			if u.Host != "github.com" {
+				return name, link
+			}
+		}
+	}
+
+	if lines := lineFragment(sections); lines != "" {
+		name += "#" + lines
+		link += "#" + lines
+	}
+	return name, link
+}
+
+// lineFragment returns the line range of the imported lines in GitHub line
+// highlight format, such as "L5-L9".
+func lineFragment(sections []section) string {
+	from, to := 0, 0
+	for _, s := range sections {
+		for _, l := range s.lines {
+			if l.number == 0 {
+				continue // Separator
+			}
+			if from == 0 || l.number < from {
+				from = l.number
+			}
+			if l.number > to {
+				to = l.number
+			}
+		}
+	}
+
+	switch {
+	case from == 0:
+		return ""
+	case from == to:
+		return fmt.Sprintf("L%d", from)
+	default:
+		return fmt.Sprintf("L%d-L%d", from, to)
+	}
+}
+
+// addCaption adds the caption line to the processed data, based on the
+// caption position. When `gap` is set, an empty line is added between the
+// caption and the data.
+func (m *Marker) addCaption(data []byte, caption string, gap bool) []byte {
+	if m.Caption.Position == CaptionBelow {
+		if gap {
+			data = append(data, br)
+		}
+		data = append(data, []byte(caption)...)
+		return append(data, br)
+	}
+
+	result := append([]byte(caption), br)
+	if gap {
+		result = append(result, br)
+	}
+	return append(result, data...)
+}
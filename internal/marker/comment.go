@@ -0,0 +1,191 @@
+package marker
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// commentSyntax holds the comment syntax of a file type. Either or both of
+// line comment and block comment are set.
+type commentSyntax struct {
+	line       string
+	blockStart string
+	blockEnd   string
+
+	// blockEscape escapes the data to be placed within block comment, so
+	// that the comment is not closed by the data.
+	blockEscape *strings.Replacer
+}
+
+var (
+	hashComment  = commentSyntax{line: "#"}
+	slashComment = commentSyntax{line: "//", blockStart: "/*", blockEnd: "*/"}
+	dashComment  = commentSyntax{line: "--"}
+	htmlComment  = commentSyntax{
+		blockStart:  "<!--",
+		blockEnd:    "-->",
+		blockEscape: strings.NewReplacer("<!--", "&lt;!--", "-->", "--&gt;", "--!>", "--!&gt;"),
+	}
+)
+
+// commentSyntaxes maps file extensions to their comment syntax.
+var commentSyntaxes = map[string]commentSyntax{
+	".yaml": hashComment,
+	".yml":  hashComment,
+	".toml": hashComment,
+	".sh":   hashComment,
+	".bash": hashComment,
+	".zsh":  hashComment,
+	".py":   hashComment,
+	".rb":   hashComment,
+	".tf":   hashComment,
+	".conf": hashComment,
+
+	".go":    slashComment,
+	".js":    slashComment,
+	".ts":    slashComment,
+	".java":  slashComment,
+	".kt":    slashComment,
+	".c":     slashComment,
+	".h":     slashComment,
+	".cpp":   slashComment,
+	".cs":    slashComment,
+	".rs":    slashComment,
+	".swift": slashComment,
+	".proto": slashComment,
+	".jsonc": slashComment,
+
+	".sql": dashComment,
+	".lua": dashComment,
+	".hs":  dashComment,
+
+	".md":   htmlComment,
+	".html": htmlComment,
+	".xml":  htmlComment,
+}
+
+// commentSyntaxFor returns the comment syntax based on the file name. Some
+// well-known file names without extension are also supported.
+func commentSyntaxFor(file string) (commentSyntax, bool) {
+	name := strings.ToLower(path.Base(filepath.ToSlash(file)))
+	switch {
+	case strings.HasPrefix(name, "dockerfile"), name == "makefile":
+		return hashComment, true
+	}
+
+	c, found := commentSyntaxes[path.Ext(name)]
+	return c, found
+}
+
+// isComment checks whether the whole line is a comment.
+func (c commentSyntax) isComment(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	if c.line != "" && strings.HasPrefix(trimmed, c.line) {
+		return true
+	}
+	return c.blockStart != "" &&
+		strings.HasPrefix(trimmed, c.blockStart) &&
+		strings.HasSuffix(trimmed, c.blockEnd) &&
+		len(trimmed) >= len(c.blockStart)+len(c.blockEnd)
+}
+
+// trimTrailingComment removes the line comment following the code. Comment
+// needs to be preceded by whitespace, and comment syntax found in quoted
+// string is ignored.
+func (c commentSyntax) trimTrailingComment(text string) string {
+	if c.line == "" {
+		return text
+	}
+
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case quote != 0 && ch == '\\':
+			i++ // Skip escaped character
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case i > 0 && (text[i-1] == ' ' || text[i-1] == '\t') &&
+			strings.HasPrefix(text[i:], c.line):
+			return strings.TrimRight(text[:i], " \t")
+		}
+	}
+	return text
+}
+
+// stripComments removes the comment lines from the imported content, based on
+// the comment syntax of the import target. When `trailing` is set, comments
+// following the code are also removed.
+func stripComments(sections []section, targetFile string, trailing bool) []section {
+	c, found := commentSyntaxFor(targetFile)
+	if !found {
+		return sections
+	}
+
+	result := make([]section, 0, len(sections))
+	for _, s := range sections {
+		lines := []line{}
+		for _, l := range s.lines {
+			if c.isComment(l.text) {
+				continue
+			}
+			if trailing {
+				l.text = c.trimTrailingComment(l.text)
+			}
+			lines = append(lines, l)
+		}
+		s.lines = lines
+		result = append(result, s)
+	}
+	return result
+}
+
+// commentOut comments out the processed data. With line comment syntax, the
+// comment is added at the smallest indentation so that the structure is kept
+// within the comment. Otherwise, the data is wrapped with block comment, and
+// any comment syntax within the data is escaped.
+func (c commentSyntax) commentOut(data []byte) []byte {
+	if len(data) == 0 {
+		return data
+	}
+
+	if c.line == "" {
+		if c.blockEscape != nil {
+			data = []byte(c.blockEscape.Replace(string(data)))
+		}
+		result := append([]byte(c.blockStart), br)
+		result = append(result, data...)
+		result = append(result, []byte(c.blockEnd)...)
+		return append(result, br)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if i := len(l) - len(strings.TrimLeft(l, " \t")); indent < 0 || i < indent {
+			indent = i
+		}
+	}
+	if indent < 0 {
+		indent = 0
+	}
+
+	result := []byte{}
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			l = strings.Repeat(" ", indent) + c.line
+		} else {
+			l = l[:indent] + c.line + " " + l[indent:]
+		}
+		result = append(result, []byte(l)...)
+		result = append(result, br)
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package marker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// convertFormat parses the imported lines as YAML or JSON, and re-encodes
+// them in the given format. As JSON is a subset of YAML, both formats are
+// parsed as YAML. The result is returned as a single section.
+func convertFormat(sections []section, format ConvertFormat) ([]section, error) {
+	lines := []string{}
+	for _, s := range sections {
+		for _, l := range s.lines {
+			lines = append(lines, l.text)
+		}
+	}
+
+	// Selected JSON lines often end with comma, as they are taken from
+	// within an object or array.
+	src := strings.TrimSuffix(strings.TrimRight(strings.Join(lines, "\n"), " \t\n"), ",")
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(src), node); err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrConvertFormat, err)
+	}
+	if len(node.Content) == 0 {
+		return []section{}, nil
+	}
+	doc := node.Content[0]
+
+	var out []byte
+	switch format {
+	case ConvertYAML:
+		resetStyle(doc)
+		buf := &bytes.Buffer{}
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("%w, %v", ErrConvertFormat, err)
+		}
+		out = buf.Bytes()
+	case ConvertJSON:
+		compact := &bytes.Buffer{}
+		if err := writeJSON(compact, doc); err != nil {
+			return nil, fmt.Errorf("%w, %v", ErrConvertFormat, err)
+		}
+		buf := &bytes.Buffer{}
+		if err := json.Indent(buf, compact.Bytes(), "", "  "); err != nil {
+			return nil, fmt.Errorf("%w, %v", ErrConvertFormat, err)
+		}
+		out = buf.Bytes()
+	}
+
+	s := section{}
+	for _, text := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		s.lines = append(s.lines, line{text: text})
+	}
+	return []section{s}, nil
+}
+
+// resetStyle removes the styles such as quotes and flow style, so that the
+// converted YAML uses the default block style.
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+// writeJSON writes the YAML node as compact JSON, keeping the order of
+// mapping keys.
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, n.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, n.Alias)
+	case yaml.MappingNode:
+		buf.WriteString("{")
+		for i := 0; i < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key, err := json.Marshal(n.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteString(":")
+			if err := writeJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	case yaml.SequenceNode:
+		buf.WriteString("[")
+		for i, c := range n.Content {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := writeJSON(buf, c); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+	case yaml.ScalarNode:
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
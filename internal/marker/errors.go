@@ -14,4 +14,10 @@ var (
 	ErrInvalidURL          = errors.New("invalid URL")
 	ErrGetMarkerTarget     = errors.New("failed to get marker target")
 	ErrNonSuccessCode      = errors.New("received non-success error code")
+
+	ErrInvalidGoSource       = errors.New("invalid Go source")
+	ErrNoMatchingDeclaration = errors.New("no matching declaration found")
+	ErrNoMatchingAnchor      = errors.New("no line matching anchor found")
+	ErrInvalidTableData      = errors.New("invalid table data")
+	ErrConvertFormat         = errors.New("failed to convert format")
 )
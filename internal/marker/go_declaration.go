@@ -0,0 +1,108 @@
+package marker
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// GoDecl holds the details of Go declaration to import. This is used with
+// GoDeclaration import logic, where the line range is only known after parsing
+// the import target.
+type GoDecl struct {
+	// Kind is one of token.FUNC, token.TYPE, token.CONST or token.VAR.
+	Kind token.Token
+
+	// Name is the declared name. For methods, this can be provided with the
+	// receiver type name, e.g. "Marker.ProcessMarkerData".
+	Name string
+
+	// WithoutDoc removes the doc comment from the imported declaration.
+	WithoutDoc bool
+}
+
+// findGoDeclaration parses the Go source, and returns the line range of the
+// declaration matching decl.
+//
+// For const, var and type declarations, the whole declaration block is
+// returned even when the name is found in a grouped declaration.
+func findGoDeclaration(src []byte, decl *GoDecl) (int, int, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w, %v", ErrInvalidGoSource, err)
+	}
+
+	for _, d := range f.Decls {
+		var doc *ast.CommentGroup
+
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if decl.Kind != token.FUNC || funcDeclName(d) != decl.Name {
+				continue
+			}
+			doc = d.Doc
+		case *ast.GenDecl:
+			if d.Tok != decl.Kind || !genDeclHasName(d, decl.Name) {
+				continue
+			}
+			doc = d.Doc
+		default:
+			continue
+		}
+
+		from := fset.Position(d.Pos()).Line
+		if doc != nil && !decl.WithoutDoc {
+			from = fset.Position(doc.Pos()).Line
+		}
+		to := fset.Position(d.End()).Line
+		return from, to, nil
+	}
+
+	return 0, 0, fmt.Errorf("%w for '%s %s'", ErrNoMatchingDeclaration, decl.Kind, decl.Name)
+}
+
+// funcDeclName returns the name of function. For methods, the name is
+// prefixed with the receiver type name, such as "Marker.ProcessMarkerData".
+func funcDeclName(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name
+	}
+
+	recv := d.Recv.List[0].Type
+	for {
+		switch r := recv.(type) {
+		case *ast.StarExpr:
+			recv = r.X
+			continue
+		case *ast.IndexExpr:
+			recv = r.X
+			continue
+		case *ast.IndexListExpr:
+			recv = r.X
+			continue
+		case *ast.Ident:
+			return r.Name + "." + d.Name.Name
+		}
+		return d.Name.Name
+	}
+}
+
+func genDeclHasName(d *ast.GenDecl, name string) bool {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if s.Name.Name == name {
+				return true
+			}
+		case *ast.ValueSpec:
+			for _, n := range s.Names {
+				if n.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package marker
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	atxHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*))?$`)
+	setextHeading = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	listItem      = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s`)
+)
+
+const maxHeadingLevel = 6
+
+// heading holds the heading information found in the imported lines.
+type heading struct {
+	index int // Index of the heading text line
+	level int
+	text  string
+
+	// setext is set when the heading is written with underline, in which
+	// case the underline is found at index + 1.
+	setext bool
+}
+
+// shiftHeadings updates Markdown heading levels in the imported lines. Both
+// ATX headings (e.g. "## Title") and setext headings (title with "===" or
+// "---" underline) are updated, and setext headings are converted to ATX
+// headings as setext cannot represent level 3 or deeper. Lines within fenced
+// code blocks are left untouched.
+func shiftHeadings(lines []line, h *Headings) []line {
+	headings := findHeadings(lines)
+	if len(headings) == 0 {
+		return lines
+	}
+
+	shift := h.Level
+	if h.Mode == BaseHeadings {
+		top := maxHeadingLevel
+		for _, x := range headings {
+			if x.level < top {
+				top = x.level
+			}
+		}
+		shift = h.Level - top
+	}
+
+	result := []line{}
+	next := 0
+	for i := 0; i < len(lines); i++ {
+		if next >= len(headings) || headings[next].index != i {
+			result = append(result, lines[i])
+			continue
+		}
+
+		x := headings[next]
+		next++
+
+		level := x.level + shift
+		switch {
+		case level < 1:
+			level = 1
+		case level > maxHeadingLevel:
+			level = maxHeadingLevel
+		}
+
+		text := strings.Repeat("#", level)
+		if x.text != "" {
+			text += " " + x.text
+		}
+		result = append(result, line{number: lines[i].number, text: text})
+
+		if x.setext {
+			i++ // Skip underline
+		}
+	}
+	return result
+}
+
+// findHeadings returns all the headings found outside of fenced code blocks.
+func findHeadings(lines []line) []heading {
+	headings := []heading{}
+
+	inCode := markdownCodeBlocks(lines)
+	for i := 0; i < len(lines); i++ {
+		text := lines[i].text
+		if inCode[i] {
+			continue
+		}
+
+		if ms := atxHeading.FindStringSubmatch(text); ms != nil {
+			headings = append(headings, heading{
+				index: i,
+				level: len(ms[1]),
+				text:  strings.TrimSpace(ms[2]),
+			})
+			continue
+		}
+
+		// Setext heading requires non-empty text line, followed by underline.
+		if i+1 < len(lines) && strings.TrimSpace(text) != "" &&
+			!strings.HasPrefix(text, "    ") && !listItem.MatchString(text) {
+			if ms := setextHeading.FindStringSubmatch(lines[i+1].text); ms != nil && !inCode[i+1] {
+				level := 1
+				if ms[1][0] == '-' {
+					level = 2
+				}
+				headings = append(headings, heading{
+					index:  i,
+					level:  level,
+					text:   strings.TrimSpace(text),
+					setext: true,
+				})
+				i++
+			}
+		}
+	}
+
+	return headings
+}
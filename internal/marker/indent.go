@@ -5,7 +5,7 @@ import (
 	"strings"
 )
 
-func adjustIndentation(lineData []byte, exporterMarkerIndent int, importerIndentation *Indentation) []byte {
+func adjustIndentation(lineData []byte, exporterMarkerIndent int, importerIndentation *Indentation, char byte) []byte {
 	// If no indentation setup is done, simply return as is
 	if importerIndentation == nil {
 		lineData = append(lineData, br)
@@ -16,12 +16,13 @@ func adjustIndentation(lineData []byte, exporterMarkerIndent int, importerIndent
 	// Absolute adjustment takes precedence over extra indentation.
 	switch importerIndentation.Mode {
 	case AbsoluteIndentation:
-		lineData = handleAbsoluteIndentation(lineData, exporterMarkerIndent, importerIndentation.Length)
+		lineData = handleAbsoluteIndentation(lineData, exporterMarkerIndent, importerIndentation.Length, char)
 	case ExtraIndentation:
-		lineData = prependWhitespaces(lineData, importerIndentation.Length)
+		lineData = prependWhitespaces(lineData, importerIndentation.Length, char)
 	case AlignIndentation:
-		lineData = handleAbsoluteIndentation(lineData, exporterMarkerIndent, importerIndentation.MarkerIndentation)
+		lineData = handleAbsoluteIndentation(lineData, exporterMarkerIndent, importerIndentation.MarkerIndentation, char)
 	case KeepIndentation: // Explicitly handling this, as it is likely that the default behaviour woulld need to change
+	case DedentIndentation: // Handled for all lines beforehand with dedentSections
 	}
 	lineData = append(lineData, br)
 	return lineData
@@ -48,11 +49,15 @@ func adjustIndentation(lineData []byte, exporterMarkerIndent int, importerIndent
 // For the Case 1. and 2., the diff needs to be calculated to ensure correct
 // indentation.
 //
-// For the Case 3., it's not clear what we should expect. This is currently not
-// handled, and it may need to be an error.
-func handleAbsoluteIndentation(lineData []byte, exportMarkerIndent, targetIndent int) []byte {
+// For the Case 3., it's not clear what we should expect. This is currently
+// only handled by not stripping more than the preceding whitespaces, so that
+// the line content itself is not removed.
+//
+// Indentation is counted by the number of whitespace characters, and thus a
+// tab is counted as a single indentation. When prepending, `char` is used.
+func handleAbsoluteIndentation(lineData []byte, exportMarkerIndent, targetIndent int, char byte) []byte {
 	lineString := string(lineData)
-	currenttIndent := len(lineString) - len(strings.TrimLeft(lineString, " "))
+	currenttIndent := len(lineString) - len(strings.TrimLeft(lineString, " \t"))
 
 	switch {
 	// Case 1.
@@ -60,6 +65,11 @@ func handleAbsoluteIndentation(lineData []byte, exportMarkerIndent, targetIndent
 	// extra indentations.
 	case exportMarkerIndent >= targetIndent:
 		indentAdjustment := exportMarkerIndent - targetIndent
+		// Do not strip more than the preceding whitespaces, which can happen
+		// with empty line, or line with less indentation than the base.
+		if currenttIndent < indentAdjustment {
+			indentAdjustment = currenttIndent
+		}
 		return lineData[indentAdjustment:]
 
 	// Case 2.
@@ -67,7 +77,7 @@ func handleAbsoluteIndentation(lineData []byte, exportMarkerIndent, targetIndent
 	// the indent diff.
 	case exportMarkerIndent < targetIndent:
 		indentAdjustment := targetIndent - exportMarkerIndent
-		return prependWhitespaces(lineData, indentAdjustment)
+		return prependWhitespaces(lineData, indentAdjustment, char)
 
 	// Case 3.
 	case currenttIndent < exportMarkerIndent:
@@ -76,12 +86,12 @@ func handleAbsoluteIndentation(lineData []byte, exportMarkerIndent, targetIndent
 	return lineData
 }
 
-func prependWhitespaces(x []byte, count int) []byte {
+func prependWhitespaces(x []byte, count int, char byte) []byte {
 	// If provided line only has space chars, return the line data as is.
 	if len(bytes.TrimSpace(x)) == 0 {
 		return x
 	}
-	empty := bytes.Repeat([]byte(" "), count)
+	empty := bytes.Repeat([]byte{char}, count)
 	// x = append(x, empty...)
 	// copy(x[count:], x)
 	// copy(x, empty)
@@ -89,3 +99,132 @@ func prependWhitespaces(x []byte, count int) []byte {
 
 	return append(empty, x...)
 }
+
+// dedentSections removes the common leading whitespace from all the lines in
+// sections, and then adds `extra` indentation using `char`. Lines with only
+// whitespace become empty. Lines not from the target file, such as separator,
+// do not affect the common leading whitespace.
+func dedentSections(sections []section, extra int, char byte) []section {
+	common := ""
+	found := false
+	for _, s := range sections {
+		for _, l := range s.lines {
+			if l.number == 0 || strings.TrimSpace(l.text) == "" {
+				continue
+			}
+			indent := l.text[:len(l.text)-len(strings.TrimLeft(l.text, " \t"))]
+			if !found {
+				common = indent
+				found = true
+				continue
+			}
+			common = commonPrefix(common, indent)
+		}
+	}
+
+	result := make([]section, 0, len(sections))
+	for _, s := range sections {
+		lines := make([]line, 0, len(s.lines))
+		for _, l := range s.lines {
+			text := strings.TrimPrefix(l.text, common)
+			if strings.TrimSpace(text) == "" {
+				text = ""
+			}
+			text = string(prependWhitespaces([]byte(text), extra, char))
+			lines = append(lines, line{number: l.number, text: text})
+		}
+		s.lines = lines
+		result = append(result, s)
+	}
+	return result
+}
+
+func commonPrefix(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return a[:i]
+}
+
+// firstLineIndentation returns the indentation of the first non-empty line.
+func firstLineIndentation(lines []line) int {
+	for _, l := range lines {
+		if strings.TrimSpace(l.text) == "" {
+			continue
+		}
+		return len(l.text) - len(strings.TrimLeft(l.text, " \t"))
+	}
+	return 0
+}
+
+// tabWidth is used for converting between tab and space indentations.
+const tabWidth = 4
+
+// indentChar returns the character to use for indentation. Unless specified
+// with the option, this is detected from the first indented line. YAML does
+// not allow tabs for indentation, and thus spaces are always used for YAML
+// importing file unless tabs are specified.
+func (i *Indentation) indentChar(sections []section, fileType string) byte {
+	if i != nil {
+		switch i.Char {
+		case SpaceIndent:
+			return ' '
+		case TabIndent:
+			return '\t'
+		}
+	}
+
+	if fileType == ".yaml" || fileType == ".yml" {
+		return ' '
+	}
+
+	for _, s := range sections {
+		for _, l := range s.lines {
+			if strings.TrimSpace(l.text) == "" {
+				continue
+			}
+			if l.text[0] == ' ' || l.text[0] == '\t' {
+				return l.text[0]
+			}
+		}
+	}
+	return ' '
+}
+
+// convertIndentation converts the preceding whitespaces of each line to use
+// `char`. Exporter Marker indentation is also converted, so that it can be
+// compared with the lines.
+func convertIndentation(sections []section, char byte) []section {
+	result := make([]section, 0, len(sections))
+	for _, s := range sections {
+		lines := make([]line, 0, len(s.lines))
+		for _, l := range s.lines {
+			lines = append(lines, line{number: l.number, text: convertLeadingWhitespaces(l.text, char)})
+		}
+		s.lines = lines
+		s.exporterIndentation = convertLeadingWhitespaces(s.exporterIndentation, char)
+		result = append(result, s)
+	}
+	return result
+}
+
+// convertLeadingWhitespaces converts the preceding whitespaces of text to use
+// `char`. A tab is treated as tabWidth spaces for conversion.
+func convertLeadingWhitespaces(text string, char byte) string {
+	trimmed := strings.TrimLeft(text, " \t")
+	width := 0
+	for _, c := range text[:len(text)-len(trimmed)] {
+		if c == '\t' {
+			width += tabWidth - width%tabWidth
+			continue
+		}
+		width++
+	}
+
+	indent := strings.Repeat(" ", width)
+	if char == '\t' {
+		indent = strings.Repeat("\t", width/tabWidth) + strings.Repeat(" ", width%tabWidth)
+	}
+	return indent + trimmed
+}
@@ -20,6 +20,18 @@ func TestHandleAbsoluteIndentation(t *testing.T) {
 			targetIndent:         4,
 			want:                 []byte("      abcdef"), // This is 8 - 6 + 4 = 6
 		},
+		"case 3. - original data has less indent than marker": {
+			originalSlice:        []byte("  abcdef"), // 2 spaces
+			exporterMarkerIndent: 6,
+			targetIndent:         0,
+			want:                 []byte("abcdef"), // Only preceding spaces are removed
+		},
+		"case 3. - empty line": {
+			originalSlice:        []byte(""),
+			exporterMarkerIndent: 6,
+			targetIndent:         2,
+			want:                 []byte(""),
+		},
 		"case 2. - original data has less indent": {
 			originalSlice:        []byte("    abcdef"), // 4 spaces
 			exporterMarkerIndent: 2,                    // remove 2
@@ -29,7 +41,7 @@ func TestHandleAbsoluteIndentation(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got := handleAbsoluteIndentation(tc.originalSlice, tc.exporterMarkerIndent, tc.targetIndent)
+			got := handleAbsoluteIndentation(tc.originalSlice, tc.exporterMarkerIndent, tc.targetIndent, ' ')
 
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Errorf("prepend result didn't match (-want / +got)\n%s", diff)
@@ -63,7 +75,7 @@ func TestPrependWhitespaces(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got := prependWhitespaces(tc.originalSlice, tc.whitespaceCount)
+			got := prependWhitespaces(tc.originalSlice, tc.whitespaceCount, ' ')
 
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Errorf("prepend result didn't match (-want / +got)\n%s", diff)
@@ -71,3 +83,115 @@ func TestPrependWhitespaces(t *testing.T) {
 		})
 	}
 }
+
+func TestDedentSections(t *testing.T) {
+	cases := map[string]struct {
+		sections []section
+		extra    int
+
+		want []section
+	}{
+		"common indentation across sections": {
+			sections: []section{
+				{lines: []line{{1, "    abc"}, {2, "      def"}}},
+				{lines: []line{{5, "    ghi"}}},
+			},
+			want: []section{
+				{lines: []line{{1, "abc"}, {2, "  def"}}},
+				{lines: []line{{5, "ghi"}}},
+			},
+		},
+		"whitespace only line is ignored": {
+			sections: []section{
+				{lines: []line{{1, "\t\tabc"}, {2, " "}, {3, "\t\t\tdef"}}},
+			},
+			want: []section{
+				{lines: []line{{1, "abc"}, {2, ""}, {3, "\tdef"}}},
+			},
+		},
+		"mixed tabs and spaces": {
+			sections: []section{
+				{lines: []line{{1, "\t  abc"}, {2, "\t def"}}},
+			},
+			want: []section{
+				{lines: []line{{1, " abc"}, {2, "def"}}},
+			},
+		},
+		"separator is ignored": {
+			sections: []section{
+				{lines: []line{{1, "    abc"}}},
+				{lines: []line{{0, "--"}}},
+				{lines: []line{{5, "    ghi"}}},
+			},
+			want: []section{
+				{lines: []line{{1, "abc"}}},
+				{lines: []line{{0, "--"}}},
+				{lines: []line{{5, "ghi"}}},
+			},
+		},
+		"extra indentation": {
+			sections: []section{
+				{lines: []line{{1, "    abc"}, {2, ""}, {3, "      def"}}},
+			},
+			extra: 2,
+			want: []section{
+				{lines: []line{{1, "  abc"}, {2, ""}, {3, "    def"}}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := dedentSections(tc.sections, tc.extra, ' ')
+
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(section{}, line{})); diff != "" {
+				t.Errorf("dedent result didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertIndentation(t *testing.T) {
+	cases := map[string]struct {
+		sections []section
+		char     byte
+
+		want []section
+	}{
+		"tabs to spaces": {
+			sections: []section{
+				{lines: []line{{1, "abc"}, {2, "\tdef"}, {3, "\t\tghi"}}},
+			},
+			char: ' ',
+			want: []section{
+				{lines: []line{{1, "abc"}, {2, "    def"}, {3, "        ghi"}}},
+			},
+		},
+		"spaces to tabs": {
+			sections: []section{
+				{lines: []line{{1, "    abc"}, {2, "      def"}, {3, "  ghi"}}},
+			},
+			char: '\t',
+			want: []section{
+				{lines: []line{{1, "\tabc"}, {2, "\t  def"}, {3, "  ghi"}}},
+			},
+		},
+		"mixed tabs and spaces": {
+			sections: []section{
+				{lines: []line{{1, "  \tabc"}, {2, "\t  def"}}},
+			},
+			char: ' ',
+			want: []section{
+				{lines: []line{{1, "    abc"}, {2, "      def"}}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := convertIndentation(tc.sections, tc.char)
+
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(section{}, line{})); diff != "" {
+				t.Errorf("conversion result didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
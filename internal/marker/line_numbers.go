@@ -0,0 +1,53 @@
+package marker
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// prefixLineNumbers adds the original line number to each line. Line numbers
+// are right aligned, and lines not found in the import target such as
+// separator get whitespaces instead.
+func prefixLineNumbers(lines []line) []line {
+	width := 0
+	for _, l := range lines {
+		if w := len(strconv.Itoa(l.number)); w > width {
+			width = w
+		}
+	}
+
+	result := make([]line, 0, len(lines))
+	for _, l := range lines {
+		number := ""
+		if l.number != 0 {
+			number = strconv.Itoa(l.number)
+		}
+		text := fmt.Sprintf("%*s", width, number)
+		if l.text != "" {
+			text += "  " + l.text
+		}
+		result = append(result, line{number: l.number, text: text})
+	}
+	return result
+}
+
+// fenceAttributes returns the code fence attributes for line numbers, using
+// the first line number as the starting line.
+func (n *LineNumbers) fenceAttributes(lines []line) string {
+	start := 1
+	for _, l := range lines {
+		if l.number != 0 {
+			start = l.number
+			break
+		}
+	}
+
+	switch n.Mode {
+	case HugoLineNumbers:
+		return fmt.Sprintf(" {linenos=true,linenostart=%d}", start)
+	case MDXLineNumbers:
+		return fmt.Sprintf(" showLineNumbers{%d}", start)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,154 @@
+package marker
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	codeFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+	inlineLink    = regexp.MustCompile(`(\]\(\s*<?)([^)\s>]+)`)
+	referenceLink = regexp.MustCompile(`^( {0,3}\[[^\]]+\]:\s*<?)([^\s>]+)`)
+	urlScheme     = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// fenceLanguages maps file extensions to code fence language, where the
+// extension itself is not the commonly used language name.
+var fenceLanguages = map[string]string{
+	"yml":  "yaml",
+	"md":   "markdown",
+	"py":   "python",
+	"rb":   "ruby",
+	"js":   "javascript",
+	"ts":   "typescript",
+	"rs":   "rust",
+	"tf":   "hcl",
+	"txt":  "",
+	"text": "",
+}
+
+// fenceLanguage returns the code fence language inferred from the import
+// target file name. Extension is used as is unless found in fenceLanguages,
+// and some well-known file names without extension are also supported.
+func fenceLanguage(targetFile string) string {
+	name := path.Base(filepath.ToSlash(targetFile))
+	if u, err := url.Parse(targetFile); err == nil && u.Scheme != "" {
+		name = path.Base(u.Path)
+	}
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.HasPrefix(name, "dockerfile"):
+		return "dockerfile"
+	case name == "makefile":
+		return "makefile"
+	}
+
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	if lang, found := fenceLanguages[ext]; found {
+		return lang
+	}
+	return ext
+}
+
+// codeFenceFor returns the code fence to wrap the lines with. When the lines
+// contain backtick code fence, the returned fence is made longer than any of
+// them so that the content is kept within the code block.
+func codeFenceFor(lines []line) string {
+	fence := "```"
+	for _, l := range lines {
+		ms := codeFence.FindStringSubmatch(l.text)
+		if ms == nil || ms[1][0] != '`' || len(ms[1]) < len(fence) {
+			continue
+		}
+		fence = strings.Repeat("`", len(ms[1])+1)
+	}
+	return fence
+}
+
+// markdownCodeBlocks returns whether each line is a part of fenced code block,
+// including the fence lines themselves.
+func markdownCodeBlocks(lines []line) []bool {
+	result := make([]bool, len(lines))
+
+	fence := ""
+	for i, l := range lines {
+		if ms := codeFence.FindStringSubmatch(l.text); ms != nil {
+			switch {
+			case fence == "":
+				fence = ms[1]
+			case ms[1][0] == fence[0] && len(ms[1]) >= len(fence) &&
+				strings.TrimLeft(strings.TrimSpace(l.text), ms[1][:1]) == "":
+				fence = ""
+			}
+			result[i] = true
+			continue
+		}
+		result[i] = fence != ""
+	}
+	return result
+}
+
+// rewriteLinks updates relative link targets found in Markdown, so that they
+// point to the same location from the importing file. Inline links, images,
+// and reference definitions are updated, while links in fenced code blocks
+// are left untouched.
+//
+// `targetFile` is the import target path relative to the importing file,
+// which is the same path resolution used for reading the import target.
+func rewriteLinks(lines []line, targetFile string) []line {
+	dir := path.Dir(filepath.ToSlash(targetFile))
+	if dir == "." {
+		return lines
+	}
+
+	inCode := markdownCodeBlocks(lines)
+
+	result := make([]line, 0, len(lines))
+	for i, l := range lines {
+		if inCode[i] {
+			result = append(result, l)
+			continue
+		}
+
+		rebase := func(s string, re *regexp.Regexp) string {
+			return re.ReplaceAllStringFunc(s, func(m string) string {
+				ms := re.FindStringSubmatch(m)
+				return ms[1] + rebaseLink(dir, ms[2])
+			})
+		}
+		text := rebase(l.text, inlineLink)
+		text = rebase(text, referenceLink)
+
+		result = append(result, line{number: l.number, text: text})
+	}
+	return result
+}
+
+// rebaseLink returns the link target with `dir` prepended. Links with URL
+// scheme, absolute paths, and in-page anchors are returned as is.
+func rebaseLink(dir, target string) string {
+	if strings.HasPrefix(target, "#") ||
+		strings.HasPrefix(target, "/") ||
+		urlScheme.MatchString(target) {
+		return target
+	}
+
+	p, suffix := target, ""
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		p, suffix = target[:i], target[i:]
+	}
+
+	rebased := path.Join(dir, p)
+	if strings.HasSuffix(p, "/") {
+		rebased += "/"
+	}
+	if strings.HasPrefix(target, "./") && !strings.HasPrefix(rebased, ".") {
+		rebased = "./" + rebased
+	}
+	return rebased + suffix
+}
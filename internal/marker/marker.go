@@ -3,6 +3,7 @@ package marker
 import (
 	"errors"
 	"fmt"
+	"go/token"
 	"math"
 	"net/url"
 	"path/filepath"
@@ -31,6 +32,19 @@ type Marker struct {
 	Indentation *Indentation
 	ImportStyle *ImportStyle
 	Wrap        *Wrap
+	Separator   *Separator
+	Headings    *Headings
+	Links       *Links
+	Vars        *Vars
+	Replaces    []Replace
+	Caption     *Caption
+	LineNumbers *LineNumbers
+	Trim        bool
+	Squeeze     bool
+	Strip       *Strip
+	Convert     *Convert
+	BlockScalar *BlockScalar
+	MaxLines    *MaxLines
 }
 
 type ImportTargetFileType int
@@ -53,6 +67,8 @@ const (
 	CommaSeparatedLines ImportLogicType = iota + 1
 	LineRange
 	ExporterMarker
+	GoDeclaration
+	MultipleSelectors
 )
 
 type ImportLogic struct {
@@ -64,7 +80,22 @@ type ImportLogic struct {
 	LineFrom int
 	LineTo   int
 
+	// LineFromAnchor and LineToAnchor take precedence over LineFrom and
+	// LineTo when provided.
+	LineFromAnchor *Anchor
+	LineToAnchor   *Anchor
+
 	ExporterMarker string
+
+	GoDecl *GoDecl
+
+	// Selectors holds import logic for each selector when multiple selectors
+	// are provided. Each selector is processed in order.
+	Selectors []ImportLogic
+
+	// Excludes holds import logic for lines to be removed from the imported
+	// content.
+	Excludes []ImportLogic
 }
 
 type IndentationMode int
@@ -75,6 +106,16 @@ const (
 	ExtraIndentation
 	AlignIndentation
 	KeepIndentation
+	DedentIndentation
+)
+
+type IndentChar int
+
+const (
+	// 0 value is used for detecting the indentation character from the
+	// imported content
+	SpaceIndent IndentChar = iota + 1
+	TabIndent
 )
 
 // Indentation holds additional indentation handling option.
@@ -82,6 +123,10 @@ type Indentation struct {
 	Mode              IndentationMode
 	Length            int
 	MarkerIndentation int
+
+	// Char is the character used for indentation. When specified, the
+	// indentation in the imported content is converted to use Char.
+	Char IndentChar
 }
 
 type StyleMode int
@@ -89,20 +134,148 @@ type StyleMode int
 const (
 	// Reserve 0 value as invalid
 	Quote StyleMode = iota + 1
-
-	// TODO: implement this if there is a use case
-	// UnorderedList
-	// OrderedList
+	UnorderedList
+	OrderedList
+	TaskList
+	Details
+	Table
+	EscapeHTML
+	EscapeMarkdownTable
+	Comment
 )
 
 type ImportStyle struct {
 	Mode StyleMode
+
+	// Summary is used for Details style, and is shown as the summary of the
+	// collapsed content.
+	Summary string
 }
 
 type Wrap struct {
 	LanguageType string
 }
 
+type HeadingsMode int
+
+const (
+	// Reserve 0 value as invalid
+	RelativeHeadings HeadingsMode = iota + 1
+	BaseHeadings
+)
+
+// Headings holds Markdown heading level adjustment option. With
+// RelativeHeadings, Level is added to each heading level. With BaseHeadings,
+// the top level heading in the imported content becomes Level, and others are
+// shifted accordingly.
+type Headings struct {
+	Mode  HeadingsMode
+	Level int
+}
+
+type LinksMode int
+
+const (
+	// Reserve 0 value as invalid
+	RewriteLinks LinksMode = iota + 1
+	KeepLinks
+)
+
+// Links holds relative link handling option for Markdown. When not specified,
+// links are rewritten only when importing Markdown into Markdown.
+type Links struct {
+	Mode LinksMode
+}
+
+// Separator is inserted between each imported piece when multiple selectors
+// are used.
+type Separator struct {
+	Line string
+}
+
+// Vars holds the variables used for substituting placeholders in the
+// imported content, such as "{{name}}".
+type Vars struct {
+	Values map[string]string
+}
+
+// Replace holds search and replace logic for the imported content. Pattern
+// is Go regular expression, and Replacement can refer to submatches such as
+// "$1".
+type Replace struct {
+	Pattern     string
+	Replacement string
+}
+
+type CaptionPosition int
+
+const (
+	// Reserve 0 value as invalid
+	CaptionAbove CaptionPosition = iota + 1
+	CaptionBelow
+)
+
+// Caption holds source attribution option, which adds a link to the import
+// target either above or below the imported content.
+type Caption struct {
+	Position CaptionPosition
+}
+
+type LineNumbersMode int
+
+const (
+	// Reserve 0 value as invalid
+	PrefixLineNumbers LineNumbersMode = iota + 1
+	HugoLineNumbers
+	MDXLineNumbers
+)
+
+// LineNumbers holds how the original line numbers are presented in the
+// imported content. Prefix mode adds the line number to each line, and other
+// modes add code fence attributes for the given renderer.
+type LineNumbers struct {
+	Mode LineNumbersMode
+}
+
+// Strip holds comment removal option. Comment syntax is based on the import
+// target file type.
+type Strip struct {
+	// Trailing removes comments following the code, in addition to the lines
+	// with only comments.
+	Trailing bool
+}
+
+type ConvertFormat int
+
+const (
+	// Reserve 0 value as invalid
+	ConvertYAML ConvertFormat = iota + 1
+	ConvertJSON
+)
+
+// Convert holds the format to convert the imported content into. JSON and
+// YAML are supported as the import target.
+type Convert struct {
+	Format ConvertFormat
+}
+
+// BlockScalar holds the YAML key used for embedding the imported content as
+// literal block scalar, such as "KEY: |". Indentation is the indentation of
+// the Importer Marker, which is used for the key.
+type BlockScalar struct {
+	Key         string
+	Indentation int
+}
+
+// MaxLines holds the maximum number of lines to import. When the imported
+// content is truncated, Indicator is added as the last line, or a link to the
+// import target is added when Link is set.
+type MaxLines struct {
+	Count     int
+	Indicator string
+	Link      bool
+}
+
 func NewMarker(raw *RawMarker) (*Marker, error) {
 	err := raw.Validate()
 	if err != nil {
@@ -134,6 +307,61 @@ func NewMarker(raw *RawMarker) (*Marker, error) {
 		return nil, err
 	}
 
+	err = marker.processSeparator(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processHeadings(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processLinks(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processVars(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processReplaces(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processCaption(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processLineNumbers(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processBlankLines(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processStrip(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processConvert(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = marker.processMaxLines(raw)
+	if err != nil {
+		return nil, err
+	}
+
 	return marker, nil
 }
 
@@ -175,18 +403,45 @@ func (marker *Marker) processIndentOption(match *RawMarker) error {
 				Mode:              AlignIndentation,
 				MarkerIndentation: markerIndentation,
 			}
-			return nil // Align option does not care length information
 		case "keep":
 			// Keep the provided indentation, and do nothing
 			marker.Indentation = &Indentation{
 				Mode: KeepIndentation,
 			}
-			return nil
+		case "dedent":
+			// Remove common indentation, and optionally add extra
+			// indentation based on length information
+			marker.Indentation = &Indentation{Mode: DedentIndentation}
 		default:
 			return errors.New("unsupported indentation mode") // This shouldn't happen with the underlying regex
 		}
 	}
 
+	if char, found := matches["importer_indent_char"]; found {
+		switch char {
+		case "tabs":
+			marker.Indentation.Char = TabIndent
+		case "spaces":
+			marker.Indentation.Char = SpaceIndent
+		}
+	}
+
+	// Imported data is converted to the specified character, and thus the
+	// Importer Marker indentation needs to be counted in the same way.
+	if marker.Indentation.Mode == AlignIndentation && marker.Indentation.Char != 0 {
+		char := marker.Indentation.indentChar(nil, "")
+		marker.Indentation.MarkerIndentation = len(convertLeadingWhitespaces(match.PrecedingIndentation, char))
+	}
+
+	switch marker.Indentation.Mode {
+	case AlignIndentation, KeepIndentation:
+		return nil // Align and keep options do not care length information
+	case DedentIndentation:
+		if matches["importer_indent_length"] == "" {
+			return nil // Extra indentation is optional for dedent
+		}
+	}
+
 	if lengthInput, found := matches["importer_indent_length"]; found {
 		// Indentation length can be handled only when indentation mode
 		// is specified. As RegEx handling should start from mode handling,
@@ -210,9 +465,38 @@ func (m *Marker) processStyle(match *RawMarker) error {
 
 	if styleMode, found := matches["importer_style"]; found {
 		switch styleMode {
-		case "quote":
+		case "quote", "q":
 			m.ImportStyle = &ImportStyle{Mode: Quote}
-		case "verbatim":
+		case "ul":
+			m.ImportStyle = &ImportStyle{Mode: UnorderedList}
+		case "ol":
+			m.ImportStyle = &ImportStyle{Mode: OrderedList}
+		case "todo":
+			m.ImportStyle = &ImportStyle{Mode: TaskList}
+		case "table":
+			m.ImportStyle = &ImportStyle{Mode: Table}
+		case "escape-html":
+			m.ImportStyle = &ImportStyle{Mode: EscapeHTML}
+		case "escape-md-table":
+			m.ImportStyle = &ImportStyle{Mode: EscapeMarkdownTable}
+		case "comment":
+			m.ImportStyle = &ImportStyle{Mode: Comment}
+		case "block-scalar":
+			key := matches["importer_style_summary"]
+			if key == "" {
+				key = matches["importer_style_lang"]
+			}
+			if key == "" {
+				key = m.Name
+			}
+			m.BlockScalar = &BlockScalar{Key: key, Indentation: len(match.PrecedingIndentation)}
+		case "details":
+			summary := matches["importer_style_summary"]
+			if summary == "" {
+				summary = matches["importer_style_lang"]
+			}
+			m.ImportStyle = &ImportStyle{Mode: Details, Summary: summary}
+		case "verbatim", "v":
 			lang, found := matches["importer_style_lang"]
 			if !found {
 				m.Wrap = &Wrap{} // default verbatim, without language syntax
@@ -237,11 +521,234 @@ func (m *Marker) processWrap(match *RawMarker) error {
 		w.LanguageType = lang
 	}
 
+	// Literal wrap is handled as block scalar for YAML, using the marker name
+	// as the key. Wrap is kept so that other file types can use it as is.
+	if w.LanguageType == "literal" {
+		m.BlockScalar = &BlockScalar{Key: m.Name, Indentation: len(match.PrecedingIndentation)}
+	}
+
 	m.Wrap = w
 
 	return nil
 }
 
+func (m *Marker) processSeparator(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionSeparator)
+	if err != nil {
+		return nil // Separator is not required, and thus simply ignore if no match
+	}
+
+	s := &Separator{}
+	if quoted, found := matches["importer_separator_quoted"]; found && quoted != "" {
+		s.Line = quoted
+	}
+	if sep, found := matches["importer_separator"]; found && sep != "" {
+		s.Line = sep
+	}
+
+	m.Separator = s
+
+	return nil
+}
+
+func (m *Marker) processHeadings(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionHeadings)
+	if err != nil {
+		return nil // Headings option is not required, and thus simply ignore if no match
+	}
+
+	if shift, found := matches["importer_headings_shift"]; found && shift != "" {
+		level, err := strconv.Atoi(shift)
+		if err != nil {
+			return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, m.Name, err)
+		}
+		m.Headings = &Headings{Mode: RelativeHeadings, Level: level}
+	}
+	if base, found := matches["importer_headings_base"]; found && base != "" {
+		level, err := strconv.Atoi(base)
+		if err != nil {
+			return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, m.Name, err)
+		}
+		if level < 1 || level > maxHeadingLevel {
+			return fmt.Errorf("%w for '%s', heading level must be between 1 and %d", ErrInvalidSyntax, m.Name, maxHeadingLevel)
+		}
+		m.Headings = &Headings{Mode: BaseHeadings, Level: level}
+	}
+
+	return nil
+}
+
+func (m *Marker) processLinks(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionLinks)
+	if err != nil {
+		return nil // Links option is not required, and thus simply ignore if no match
+	}
+
+	if mode, found := matches["importer_links_mode"]; found {
+		switch mode {
+		case "rewrite":
+			m.Links = &Links{Mode: RewriteLinks}
+		case "keep":
+			m.Links = &Links{Mode: KeepLinks}
+		default:
+			return errors.New("unsupported links mode") // This shouldn't happen with the underlying regex
+		}
+	}
+
+	return nil
+}
+
+func (m *Marker) processVars(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionVars)
+	if err != nil {
+		return nil // Vars option is not required, and thus simply ignore if no match
+	}
+
+	v := &Vars{Values: map[string]string{}}
+	for _, pair := range strings.Split(matches["importer_vars"], ",") {
+		name, value, found := strings.Cut(pair, "=")
+		if !found || !varName.MatchString(name) {
+			return fmt.Errorf("%w for '%s', invalid variable '%s'", ErrInvalidSyntax, m.Name, pair)
+		}
+		v.Values[name] = value
+	}
+
+	m.Vars = v
+
+	return nil
+}
+
+func (m *Marker) processReplaces(match *RawMarker) error {
+	re := regexp.MustCompile(OptionReplace)
+	for _, ms := range re.FindAllStringSubmatch(match.Options, -1) {
+		r := Replace{
+			Pattern:     ms[re.SubexpIndex("importer_replace_pattern")],
+			Replacement: strings.ReplaceAll(ms[re.SubexpIndex("importer_replace_replacement")], `\/`, "/"),
+		}
+		if r.Pattern == "" {
+			return fmt.Errorf("%w for '%s', empty replace pattern", ErrInvalidSyntax, m.Name)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, m.Name, err)
+		}
+		m.Replaces = append(m.Replaces, r)
+	}
+
+	return nil
+}
+
+func (m *Marker) processCaption(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionCaption)
+	if err != nil {
+		return nil // Caption option is not required, and thus simply ignore if no match
+	}
+
+	switch matches["importer_caption_position"] {
+	case "", "above":
+		m.Caption = &Caption{Position: CaptionAbove}
+	case "below":
+		m.Caption = &Caption{Position: CaptionBelow}
+	default:
+		return errors.New("unsupported caption position") // This shouldn't happen with the underlying regex
+	}
+
+	return nil
+}
+
+func (m *Marker) processLineNumbers(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionLineNumbers)
+	if err != nil {
+		return nil // Line numbers option is not required, and thus simply ignore if no match
+	}
+
+	switch matches["importer_linenos_mode"] {
+	case "prefix":
+		m.LineNumbers = &LineNumbers{Mode: PrefixLineNumbers}
+	case "hugo":
+		m.LineNumbers = &LineNumbers{Mode: HugoLineNumbers}
+	case "mdx":
+		m.LineNumbers = &LineNumbers{Mode: MDXLineNumbers}
+	default:
+		return errors.New("unsupported line numbers mode") // This shouldn't happen with the underlying regex
+	}
+
+	return nil
+}
+
+func (m *Marker) processBlankLines(match *RawMarker) error {
+	if matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionTrim); err == nil {
+		m.Trim = matches["importer_trim"] == "true"
+	}
+	if matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionSqueeze); err == nil {
+		m.Squeeze = matches["importer_squeeze"] == "true"
+	}
+
+	return nil
+}
+
+func (m *Marker) processStrip(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionStripComments)
+	if err != nil {
+		return nil // Strip option is not required, and thus simply ignore if no match
+	}
+
+	m.Strip = &Strip{
+		Trailing: matches["importer_strip_trailing"] != "",
+	}
+
+	return nil
+}
+
+func (m *Marker) processConvert(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionConvert)
+	if err != nil {
+		return nil // Convert option is not required, and thus simply ignore if no match
+	}
+
+	switch matches["importer_convert_format"] {
+	case "yaml":
+		m.Convert = &Convert{Format: ConvertYAML}
+	case "json":
+		m.Convert = &Convert{Format: ConvertJSON}
+	default:
+		return errors.New("unsupported convert format") // This shouldn't happen with the underlying regex
+	}
+
+	// Converted data has no indentation to keep from the target file, and
+	// thus it is aligned with the Importer Marker unless specified otherwise.
+	if m.Indentation == nil {
+		m.Indentation = &Indentation{
+			Mode:              AlignIndentation,
+			MarkerIndentation: len(match.PrecedingIndentation),
+		}
+	}
+
+	return nil
+}
+
+func (m *Marker) processMaxLines(match *RawMarker) error {
+	matches, err := regexpplus.MapWithNamedSubgroups(match.Options, OptionMaxLines)
+	if err != nil {
+		return nil // Max lines option is not required, and thus simply ignore if no match
+	}
+
+	count, err := strconv.Atoi(matches["importer_max_lines"])
+	if err != nil {
+		return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, m.Name, err)
+	}
+	if count < 1 {
+		return fmt.Errorf("%w for '%s', max lines must be 1 or more", ErrInvalidSyntax, m.Name)
+	}
+
+	m.MaxLines = &MaxLines{
+		Count:     count,
+		Indicator: matches["importer_max_lines_indicator"],
+		Link:      matches["importer_max_lines_link"] != "",
+	}
+
+	return nil
+}
+
 // processTargetPath processes string input of import target path.
 //
 // Target path can be 2 forms.
@@ -280,16 +787,84 @@ func processTargetPath(marker *Marker, input string) error {
 // Target detail can be in various forms.
 //   - Export marker, e.g. "[some_export_marker]", where it looks for
 //     "some_export_marker" within the target file. This can hold comma
-//     separated entries.
+//     separated entries, e.g. "[intro,usage]", to import each section in
+//     order.
 //   - Line range, e.g. "6~22" meaning line 6 to 22.
 //   - Open line range, e.g. "~22" for line 1 to 22, "6~" for line 6 to end of
 //     file.
+//   - Line range relative from the end of file, e.g. "-10~" for the last 10
+//     lines, "~-2" for all but the last 2 lines.
 //   - Line selection, e.g. "1,5,7" meaning line 1, 5 and 7.
+//   - Line range with anchors, e.g. "/^func main/~/^}/" meaning the first line
+//     matching "^func main" to the following line matching "^}". Anchors can
+//     be mixed with line numbers, and "x" suffix such as "/^}/x" excludes the
+//     matched line.
+//   - Go declaration, e.g. "func:NewMarker" for function NewMarker, including
+//     its doc comment. "type", "const" and "var" can be used in place of
+//     "func", and ":nodoc" suffix drops the doc comment.
+//   - Multiple selectors, e.g. "[header],20~30" meaning Exporter Marker
+//     "header" followed by line 20 to 30. When all the selectors are line
+//     numbers, they are handled as line selection above instead.
+//   - Exclusion, e.g. "[config]![secrets]" meaning Exporter Marker "config"
+//     without the lines in Exporter Marker "secrets". Anything after "!" is
+//     handled as exclusion, and "!" can be used more than once.
 func processTargetDetail(marker *Marker, input string) error {
+	if parts := splitTargetDetail(input, '!'); len(parts) > 1 {
+		if err := processTargetDetail(marker, parts[0]); err != nil {
+			return err
+		}
+		for _, p := range parts[1:] {
+			m := &Marker{Name: marker.Name}
+			if err := processTargetDetail(m, p); err != nil {
+				return err
+			}
+			marker.ImportLogic.Excludes = append(marker.ImportLogic.Excludes, m.ImportLogic)
+		}
+		return nil
+	}
+
 	exportMarker := regexp.MustCompile(`\[(\S+)\]`)
+	goDecl := regexp.MustCompile(`^(func|type|const|var):([^:]+)(:nodoc)?$`)
 
 	markerRegex := exportMarker.FindStringSubmatch(input)
+	goDeclRegex := goDecl.FindStringSubmatch(input)
+	selectors := splitSelectors(input)
 	switch {
+	// Handle multiple selectors, where each selector is processed separately
+	case selectors != nil:
+		logics := []ImportLogic{}
+		for _, s := range selectors {
+			m := &Marker{Name: marker.Name}
+			if err := processTargetDetail(m, s); err != nil {
+				return err
+			}
+			logics = append(logics, m.ImportLogic)
+		}
+		marker.ImportLogic = ImportLogic{
+			Type:      MultipleSelectors,
+			Selectors: logics,
+		}
+
+	// Handle Go declaration
+	case goDeclRegex != nil:
+		marker.ImportLogic = ImportLogic{
+			Type: GoDeclaration,
+			GoDecl: &GoDecl{
+				Kind:       goDeclKinds[goDeclRegex[1]],
+				Name:       goDeclRegex[2],
+				WithoutDoc: goDeclRegex[3] != "",
+			},
+		}
+
+	// Handle line range with anchors. This needs to be checked before others
+	// as anchor pattern may contain any character.
+	case isAnchorRange(input):
+		logic, err := getLineRangeWithAnchor(input)
+		if err != nil {
+			return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, marker.Name, err)
+		}
+		marker.ImportLogic = logic
+
 	// Handle export marker
 	case markerRegex != nil:
 		marker.ImportLogic = ImportLogic{
@@ -310,6 +885,9 @@ func processTargetDetail(marker *Marker, input string) error {
 				// if conversion fails, simply ignore to try processing the rest
 				lowerBound, _ := strconv.Atoi(ls[0])
 				upperBound, _ := strconv.Atoi(ls[1])
+				if lowerBound < 0 || upperBound < 0 {
+					return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, marker.Name, errNegativeLine)
+				}
 
 				// Add line numbers to the slice.
 				// This way, we can support comma separated list, etc.
@@ -323,6 +901,9 @@ func processTargetDetail(marker *Marker, input string) error {
 			if err != nil {
 				continue
 			}
+			if lineNumber < 0 {
+				return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, marker.Name, errNegativeLine)
+			}
 			targetLines = append(targetLines, lineNumber)
 		}
 
@@ -349,6 +930,9 @@ func processTargetDetail(marker *Marker, input string) error {
 		if err != nil {
 			return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, marker.Name, err)
 		}
+		if i < 0 {
+			return fmt.Errorf("%w for '%s', %v", ErrInvalidSyntax, marker.Name, errNegativeLine)
+		}
 		marker.ImportLogic = ImportLogic{
 			Type:  CommaSeparatedLines,
 			Lines: []int{i},
@@ -358,12 +942,53 @@ func processTargetDetail(marker *Marker, input string) error {
 	return nil
 }
 
+var lineNumbersOnly = regexp.MustCompile(`^[0-9~-]*$`)
+
+// splitSelectors splits the target detail into multiple selectors. Exporter
+// Marker with comma separated names, e.g. "[intro,usage]", is split into
+// separate selectors. If the input consists of line numbers only, or there is
+// only one selector, nil is returned.
+func splitSelectors(input string) []string {
+	selectors := []string{}
+	for _, s := range splitTargetDetail(input, ',') {
+		if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+			for _, name := range strings.Split(s[1:len(s)-1], ",") {
+				selectors = append(selectors, "["+name+"]")
+			}
+			continue
+		}
+		selectors = append(selectors, s)
+	}
+
+	if len(selectors) < 2 {
+		return nil
+	}
+	for _, s := range selectors {
+		if !lineNumbersOnly.MatchString(s) {
+			return selectors
+		}
+	}
+	return nil
+}
+
+var goDeclKinds = map[string]token.Token{
+	"func":  token.FUNC,
+	"type":  token.TYPE,
+	"const": token.CONST,
+	"var":   token.VAR,
+}
+
 var (
 	errLowerBound     = errors.New("invalid lower bound in line range")
 	errUpperBound     = errors.New("invalid upper bound in line range")
 	errMultipleTildes = errors.New("tilde cannot be used more than once")
+	errNegativeLine   = errors.New("negative line number can only be used in a single line range")
 )
 
+// getLineRangeWithTilde returns the lower and upper bound of line range. The
+// bounds can be negative, which are resolved relative to the end of file when
+// processing the import target. Negative upper bound excludes the given number
+// of lines from the end, e.g. "~-2" is everything but the last 2 lines.
 func getLineRangeWithTilde(input string) (int, int, error) {
 	lowerBound := 0
 	upperBound := math.MaxInt32
@@ -379,7 +1004,7 @@ func getLineRangeWithTilde(input string) (int, int, error) {
 	if lb != "" {
 		l, err := strconv.Atoi(lb)
 		if err != nil {
-			return lowerBound, upperBound, fmt.Errorf("%w, %v", errUpperBound, err)
+			return lowerBound, upperBound, fmt.Errorf("%w, %v", errLowerBound, err)
 		}
 		lowerBound = l
 	}
@@ -387,7 +1012,7 @@ func getLineRangeWithTilde(input string) (int, int, error) {
 	if ub != "" {
 		u, err := strconv.Atoi(ub)
 		if err != nil {
-			return lowerBound, upperBound, fmt.Errorf("%w, %v", errLowerBound, err)
+			return lowerBound, upperBound, fmt.Errorf("%w, %v", errUpperBound, err)
 		}
 		upperBound = u
 	}
@@ -13,14 +13,77 @@ var (
 	ImporterMarkerYAML = `(?P<importer_marker_indentation>.*)# == (imptr|import|importer|i): (?P<importer_name>\S+) \/ (?P<importer_marker>begin|end)(?P<importer_option>.*) ==`
 
 	// OptionFilePathIndicator is the pattern used for parsing Importer file options.
-	OptionFilePathIndicator = `from: (?P<importer_target_path>\S+)\s*\#(?P<importer_target_detail>[0-9a-zA-Z,-_\~]+)\s?`
+	//
+	// Target detail can contain anchors with regular expression, such as
+	// "/^func main/", which can contain any characters but slash.
+	OptionFilePathIndicator = `from: (?P<importer_target_path>\S+)\s*\#(?P<importer_target_detail>(?:/(?:[^/\\]|\\.)*/|[0-9a-zA-Z!,-_\~])+)\s?`
 
 	// OptionIndentMode is the pattern used for specifying indentation mode.
-	OptionIndentMode = `indent: (?P<importer_indent_mode>absolute|extra|align|keep)\s?(?P<importer_indent_length>\d*)`
+	//
+	// Dedent mode can be combined with extra indentation, such as
+	// "indent: dedent extra 4". Indentation character can be specified at the
+	// end, such as "indent: extra 1 tabs".
+	OptionIndentMode = `indent: (?P<importer_indent_mode>absolute|extra|align|keep|dedent)(?: extra)?\s?(?P<importer_indent_length>\d*)\s?(?P<importer_indent_char>tabs|spaces)?`
 
-	OptionStyleAndWrap = `style: (?P<importer_style>quote|q|verbatim|v)\s?(?P<importer_style_lang>\S*)`
+	// OptionStyleAndWrap is the pattern used for specifying the style. Summary
+	// for details style can be quoted to contain whitespaces, such as
+	// `style: details "Full configuration"`. Unquoted value cannot contain
+	// colon, so that the following option such as `wrap: yaml` is not taken
+	// as the value.
+	OptionStyleAndWrap = `style: (?P<importer_style>quote|q|verbatim|v|ul|ol|todo|details|table|escape-html|escape-md-table|comment|block-scalar)\s?(?:"(?P<importer_style_summary>[^"]*)"|(?P<importer_style_lang>[^\s:]*)(?:\s|$))`
 	OptionWrap         = `wrap: (?P<importer_wrap_lang>\S*)`
 
+	// OptionSeparator is the pattern used for specifying the line inserted
+	// between multiple selectors. Quotes can be used for whitespaces.
+	OptionSeparator = `separator: (?:"(?P<importer_separator_quoted>[^"]*)"|(?P<importer_separator>\S+))`
+
+	// OptionHeadings is the pattern used for adjusting Markdown heading
+	// levels, either relative shift (e.g. "+2") or base level (e.g. "base 3").
+	OptionHeadings = `headings: (?:(?P<importer_headings_shift>[+-]\d+)|base (?P<importer_headings_base>\d+))`
+
+	// OptionLinks is the pattern used for specifying relative link handling.
+	OptionLinks = `links: (?P<importer_links_mode>rewrite|keep)`
+
+	// OptionVars is the pattern used for passing variables to substitute
+	// placeholders such as "{{name}}" in the imported content.
+	OptionVars = `vars: (?P<importer_vars>\S+)`
+
+	// OptionReplace is the pattern used for search and replace on the imported
+	// content, such as "replace: /example\.com/my-site.com/". Slash can be
+	// used in pattern and replacement by escaping it with backslash. This
+	// option can be specified multiple times.
+	OptionReplace = `replace: /(?P<importer_replace_pattern>(?:[^/\\]|\\.)*)/(?P<importer_replace_replacement>(?:[^/\\]|\\.)*)/`
+
+	// OptionCaption is the pattern used for adding source attribution to the
+	// imported content.
+	OptionCaption = `caption: auto(?: (?P<importer_caption_position>above|below))?`
+
+	// OptionLineNumbers is the pattern used for annotating the imported lines
+	// with the original line numbers.
+	OptionLineNumbers = `linenos: (?P<importer_linenos_mode>prefix|hugo|mdx)`
+
+	// OptionTrim is the pattern used for removing leading and trailing blank
+	// lines from the imported content.
+	OptionTrim = `trim: (?P<importer_trim>true|false)`
+
+	// OptionSqueeze is the pattern used for collapsing consecutive blank lines
+	// in the imported content into a single blank line.
+	OptionSqueeze = `squeeze: (?P<importer_squeeze>true|false)`
+
+	// OptionStripComments is the pattern used for removing comments from the
+	// imported content. Adding "trailing" also removes comments following the
+	// code, such as "strip: comments trailing".
+	OptionStripComments = `strip: comments(?: (?P<importer_strip_trailing>trailing))?`
+
+	// OptionConvert is the pattern used for converting the imported content
+	// between JSON and YAML.
+	OptionConvert = `convert: (?P<importer_convert_format>yaml|json)`
+
+	// OptionMaxLines is the pattern used for limiting the number of imported
+	// lines. Truncation indicator can be specified with quotes, or "link" to
+	// add a link to the import target.
+	OptionMaxLines = `max-lines: (?P<importer_max_lines>\d+)(?: (?:"(?P<importer_max_lines_indicator>[^"]*)"|(?P<importer_max_lines_link>link)))?`
+
 	ImporterSkipProcessingMarkdown = `<!-- == importer-skip-update == -->`
 	ImporterSkipProcessingYAML     = `# == importer-skip-update ==`
 )
@@ -2,6 +2,8 @@ package marker_test
 
 import (
 	"errors"
+	"go/token"
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -37,205 +39,1274 @@ func TestNewMarker(t *testing.T) {
 				Indentation: nil,
 			},
 		},
+		"Line range from end of file": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#-10~",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: -10,
+					LineTo:   math.MaxInt32,
+				},
+			},
+		},
+		"Line range excluding end of file": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#~-2",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 0,
+					LineTo:   -2,
+				},
+			},
+		},
 		"Line array": {
 			input: &marker.RawMarker{
 				Name:           "simple-marker",
 				IsBeginFound:   true,
 				IsEndFound:     true,
 				LineToInsertAt: 3,
-				Options:        "from: ./abc.md#3,4,5,6",
+				Options:        "from: ./abc.md#3,4,5,6",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:  marker.CommaSeparatedLines,
+					Lines: []int{3, 4, 5, 6},
+				},
+				Indentation: nil,
+			},
+		},
+		"Line array with ranges": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#3~5,7~9",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:  marker.CommaSeparatedLines,
+					Lines: []int{3, 4, 5, 7, 8, 9},
+				},
+				Indentation: nil,
+			},
+		},
+		"Exporter": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#[from-exporter-marker]",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "from-exporter-marker",
+				},
+			},
+		},
+		"Line range with anchors": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.go#/^func main/~/^}/x wrap: go",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.go",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:   marker.LineRange,
+					LineTo: math.MaxInt32,
+					LineFromAnchor: &marker.Anchor{
+						Pattern: "^func main",
+					},
+					LineToAnchor: &marker.Anchor{
+						Pattern:   "^}",
+						Exclusive: true,
+					},
+				},
+				Wrap: &marker.Wrap{
+					LanguageType: "go",
+				},
+			},
+		},
+		"Line range with anchor and line number": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#5~/^## [A-Z]+, (a|b)~c/",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 5,
+					LineTo:   math.MaxInt32,
+					LineToAnchor: &marker.Anchor{
+						Pattern: "^## [A-Z]+, (a|b)~c",
+					},
+				},
+			},
+		},
+		"Multiple exporters": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#[intro,usage]",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type: marker.MultipleSelectors,
+					Selectors: []marker.ImportLogic{
+						{
+							Type:           marker.ExporterMarker,
+							ExporterMarker: "intro",
+						},
+						{
+							Type:           marker.ExporterMarker,
+							ExporterMarker: "usage",
+						},
+					},
+				},
+			},
+		},
+		"Exporter and line range with separator": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        `from: ./abc.md#[header],20~30,35 separator: "# ..."`,
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type: marker.MultipleSelectors,
+					Selectors: []marker.ImportLogic{
+						{
+							Type:           marker.ExporterMarker,
+							ExporterMarker: "header",
+						},
+						{
+							Type:     marker.LineRange,
+							LineFrom: 20,
+							LineTo:   30,
+						},
+						{
+							Type:  marker.CommaSeparatedLines,
+							Lines: []int{35},
+						},
+					},
+				},
+				Separator: &marker.Separator{
+					Line: "# ...",
+				},
+			},
+		},
+		"Exporter with exclusions": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.yaml#[config]![secrets]!3~4",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "config",
+					Excludes: []marker.ImportLogic{
+						{
+							Type:           marker.ExporterMarker,
+							ExporterMarker: "secrets",
+						},
+						{
+							Type:     marker.LineRange,
+							LineFrom: 3,
+							LineTo:   4,
+						},
+					},
+				},
+			},
+		},
+		"Line range with line selection exclusion": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#5~20!7,9",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 5,
+					LineTo:   20,
+					Excludes: []marker.ImportLogic{
+						{
+							Type:  marker.CommaSeparatedLines,
+							Lines: []int{7, 9},
+						},
+					},
+				},
+			},
+		},
+		"Go declaration": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.go#func:Marker.ProcessMarkerData",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.go",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type: marker.GoDeclaration,
+					GoDecl: &marker.GoDecl{
+						Kind: token.FUNC,
+						Name: "Marker.ProcessMarkerData",
+					},
+				},
+			},
+		},
+		"Go declaration without doc comment": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.go#type:Marker:nodoc",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.go",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type: marker.GoDeclaration,
+					GoDecl: &marker.GoDecl{
+						Kind:       token.TYPE,
+						Name:       "Marker",
+						WithoutDoc: true,
+					},
+				},
+			},
+		},
+		"Exporter with absolute indent": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#[from-exporter-marker] indent: absolute 2",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "from-exporter-marker",
+				},
+				Indentation: &marker.Indentation{
+					Mode:   marker.AbsoluteIndentation,
+					Length: 2,
+				},
+			},
+		},
+		"Exporter with extra indent": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#[from-exporter-marker] indent: extra 4",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "from-exporter-marker",
+				},
+				Indentation: &marker.Indentation{
+					Mode:   marker.ExtraIndentation,
+					Length: 4,
+				},
+			},
+		},
+		"Exporter with indent align": {
+			input: &marker.RawMarker{
+				Name:                 "simple-marker",
+				IsBeginFound:         true,
+				IsEndFound:           true,
+				LineToInsertAt:       3,
+				Options:              "from: ./abc.yaml#[from-exporter-marker] indent: align",
+				PrecedingIndentation: "  - ", // As if yaml list input is used for indentation
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "from-exporter-marker",
+				},
+				Indentation: &marker.Indentation{
+					Mode:              marker.AlignIndentation,
+					MarkerIndentation: 4,
+				},
+			},
+		},
+		"Exporter with indent keep": {
+			input: &marker.RawMarker{
+				Name:                 "simple-marker",
+				IsBeginFound:         true,
+				IsEndFound:           true,
+				LineToInsertAt:       3,
+				Options:              "from: ./abc.yaml#[from-exporter-marker] indent: keep",
+				PrecedingIndentation: "    ",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "from-exporter-marker",
+				},
+				Indentation: &marker.Indentation{
+					Mode: marker.KeepIndentation,
+				},
+			},
+		},
+		"Dedent with extra indent": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.go#20~30 indent: dedent extra 4",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.go",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 20,
+					LineTo:   30,
+				},
+				Indentation: &marker.Indentation{
+					Mode:   marker.DedentIndentation,
+					Length: 4,
+				},
+			},
+		},
+		"Dedent without extra indent": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.go#20~30 indent: dedent",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.go",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 20,
+					LineTo:   30,
+				},
+				Indentation: &marker.Indentation{
+					Mode: marker.DedentIndentation,
+				},
+			},
+		},
+		"Extra indent with tabs": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.go#20~30 indent: extra 1 tabs",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.go",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 20,
+					LineTo:   30,
+				},
+				Indentation: &marker.Indentation{
+					Mode:   marker.ExtraIndentation,
+					Length: 1,
+					Char:   marker.TabIndent,
+				},
+			},
+		},
+		"Align with spaces": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.yaml#[xyz] indent: align spaces",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "xyz",
+				},
+				Indentation: &marker.Indentation{
+					Mode: marker.AlignIndentation,
+					Char: marker.SpaceIndent,
+				},
+			},
+		},
+		"Align with tabs": {
+			input: &marker.RawMarker{
+				Name:                 "simple-marker",
+				IsBeginFound:         true,
+				IsEndFound:           true,
+				LineToInsertAt:       3,
+				PrecedingIndentation: "        ",
+				Options:              "from: ./abc.yaml#[xyz] indent: align tabs",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "xyz",
+				},
+				Indentation: &marker.Indentation{
+					Mode:              marker.AlignIndentation,
+					MarkerIndentation: 2,
+					Char:              marker.TabIndent,
+				},
+			},
+		},
+		"Quote": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#3~5 style: quote abc", // "abc" is used as language, but quote simply ignores this
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				Indentation: nil,
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.Quote,
+				},
+			},
+		},
+		"Ordered list": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.txt#3~5 style: ol",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.txt",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.OrderedList,
+				},
+			},
+		},
+		"Task list": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.txt#3~5 style: todo",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.txt",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.TaskList,
+				},
+			},
+		},
+		"Headings with relative shift": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#3~5 headings: +2",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				Headings: &marker.Headings{
+					Mode:  marker.RelativeHeadings,
+					Level: 2,
+				},
+			},
+		},
+		"Headings with base level": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#[intro] headings: base 3",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "intro",
+				},
+				Headings: &marker.Headings{
+					Mode:  marker.BaseHeadings,
+					Level: 3,
+				},
+			},
+		},
+		"Verbatim ": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#3~5 style: verbatim some-lang",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				Indentation: nil,
+				Wrap: &marker.Wrap{
+					LanguageType: "some-lang",
+				},
+			},
+		},
+		"Details with wrap": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        `from: ./abc.yaml#3~5 style: details "Full configuration" wrap: yaml`,
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				ImportStyle: &marker.ImportStyle{
+					Mode:    marker.Details,
+					Summary: "Full configuration",
+				},
+				Wrap: &marker.Wrap{
+					LanguageType: "yaml",
+				},
+			},
+		},
+		"Details with unquoted summary": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#3~5 style: details Usage",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				ImportStyle: &marker.ImportStyle{
+					Mode:    marker.Details,
+					Summary: "Usage",
+				},
+			},
+		},
+		"Details without summary and with wrap": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.yaml#3~5 style: details wrap: yaml",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.Details,
+				},
+				Wrap: &marker.Wrap{
+					LanguageType: "yaml",
+				},
+			},
+		},
+		"Caption": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#3~5 caption: auto",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				Caption: &marker.Caption{
+					Position: marker.CaptionAbove,
+				},
+			},
+		},
+		"Caption below": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#3~5 caption: auto below",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				Caption: &marker.Caption{
+					Position: marker.CaptionBelow,
+				},
+			},
+		},
+		"Line numbers": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.go#3~5 wrap: go linenos: hugo",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.go",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				Wrap: &marker.Wrap{
+					LanguageType: "go",
+				},
+				LineNumbers: &marker.LineNumbers{
+					Mode: marker.HugoLineNumbers,
+				},
+			},
+		},
+		"Trim and squeeze": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.md#[padded] trim: true squeeze: true",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.md",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "padded",
+				},
+				Trim:    true,
+				Squeeze: true,
+			},
+		},
+		"Strip comments": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./abc.yaml#3~5 strip: comments trailing",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				Strip: &marker.Strip{
+					Trailing: true,
+				},
+			},
+		},
+		"Table": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        "from: ./matrix.csv#1~ style: table",
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.md",
+					File: "./matrix.csv",
 				},
 				ImportLogic: marker.ImportLogic{
-					Type:  marker.CommaSeparatedLines,
-					Lines: []int{3, 4, 5, 6},
+					Type:     marker.LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.Table,
 				},
-				Indentation: nil,
 			},
 		},
-		"Line array with ranges": {
+		"Convert": {
 			input: &marker.RawMarker{
 				Name:           "simple-marker",
 				IsBeginFound:   true,
 				IsEndFound:     true,
 				LineToInsertAt: 3,
-				Options:        "from: ./abc.md#3~5,7~9",
+				Options:        "from: ./openapi.json#5~12 convert: yaml indent: align",
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.md",
+					File: "./openapi.json",
 				},
 				ImportLogic: marker.ImportLogic{
-					Type:  marker.CommaSeparatedLines,
-					Lines: []int{3, 4, 5, 7, 8, 9},
+					Type:     marker.LineRange,
+					LineFrom: 5,
+					LineTo:   12,
+				},
+				Indentation: &marker.Indentation{
+					Mode: marker.AlignIndentation,
+				},
+				Convert: &marker.Convert{
+					Format: marker.ConvertYAML,
 				},
-				Indentation: nil,
 			},
 		},
-		"Exporter": {
+		"Convert with indented marker": {
+			input: &marker.RawMarker{
+				Name:                 "simple-marker",
+				IsBeginFound:         true,
+				IsEndFound:           true,
+				LineToInsertAt:       3,
+				PrecedingIndentation: "    ",
+				Options:              "from: ./openapi.json#5~12 convert: yaml",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./openapi.json",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 5,
+					LineTo:   12,
+				},
+				Indentation: &marker.Indentation{
+					Mode:              marker.AlignIndentation,
+					MarkerIndentation: 4,
+				},
+				Convert: &marker.Convert{
+					Format: marker.ConvertYAML,
+				},
+			},
+		},
+		"Escape HTML": {
 			input: &marker.RawMarker{
 				Name:           "simple-marker",
 				IsBeginFound:   true,
 				IsEndFound:     true,
 				LineToInsertAt: 3,
-				Options:        "from: ./abc.md#[from-exporter-marker]",
+				Options:        "from: ./abc.go#3~5 style: escape-html",
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.md",
+					File: "./abc.go",
 				},
 				ImportLogic: marker.ImportLogic{
-					Type:           marker.ExporterMarker,
-					ExporterMarker: "from-exporter-marker",
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.EscapeHTML,
 				},
 			},
 		},
-		"Exporter with absolute indent": {
+		"Escape Markdown table": {
 			input: &marker.RawMarker{
 				Name:           "simple-marker",
 				IsBeginFound:   true,
 				IsEndFound:     true,
 				LineToInsertAt: 3,
-				Options:        "from: ./abc.md#[from-exporter-marker] indent: absolute 2",
+				Options:        "from: ./abc.go#3~5 style: escape-md-table",
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.md",
+					File: "./abc.go",
 				},
 				ImportLogic: marker.ImportLogic{
-					Type:           marker.ExporterMarker,
-					ExporterMarker: "from-exporter-marker",
+					Type:     marker.LineRange,
+					LineFrom: 3,
+					LineTo:   5,
 				},
-				Indentation: &marker.Indentation{
-					Mode:   marker.AbsoluteIndentation,
-					Length: 2,
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.EscapeMarkdownTable,
 				},
 			},
 		},
-		"Exporter with extra indent": {
+		"Comment": {
 			input: &marker.RawMarker{
 				Name:           "simple-marker",
 				IsBeginFound:   true,
 				IsEndFound:     true,
 				LineToInsertAt: 3,
-				Options:        "from: ./abc.md#[from-exporter-marker] indent: extra 4",
+				Options:        "from: ./LICENSE#1~3 style: comment",
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.md",
+					File: "./LICENSE",
 				},
 				ImportLogic: marker.ImportLogic{
-					Type:           marker.ExporterMarker,
-					ExporterMarker: "from-exporter-marker",
+					Type:     marker.LineRange,
+					LineFrom: 1,
+					LineTo:   3,
 				},
-				Indentation: &marker.Indentation{
-					Mode:   marker.ExtraIndentation,
-					Length: 4,
+				ImportStyle: &marker.ImportStyle{
+					Mode: marker.Comment,
 				},
 			},
 		},
-		"Exporter with indent align": {
+		"Block scalar": {
 			input: &marker.RawMarker{
 				Name:                 "simple-marker",
 				IsBeginFound:         true,
 				IsEndFound:           true,
 				LineToInsertAt:       3,
-				Options:              "from: ./abc.yaml#[from-exporter-marker] indent: align",
-				PrecedingIndentation: "  - ", // As if yaml list input is used for indentation
+				PrecedingIndentation: "  ",
+				Options:              "from: ./entrypoint.sh#1~ style: block-scalar entrypoint.sh",
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.yaml",
+					File: "./entrypoint.sh",
 				},
 				ImportLogic: marker.ImportLogic{
-					Type:           marker.ExporterMarker,
-					ExporterMarker: "from-exporter-marker",
+					Type:     marker.LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				BlockScalar: &marker.BlockScalar{
+					Key:         "entrypoint.sh",
+					Indentation: 2,
+				},
+			},
+		},
+		"Block scalar without key and with indent": {
+			input: &marker.RawMarker{
+				Name:                 "entrypoint.sh",
+				IsBeginFound:         true,
+				IsEndFound:           true,
+				LineToInsertAt:       3,
+				PrecedingIndentation: "  ",
+				Options:              "from: ./entrypoint.sh#1~ style: block-scalar indent: align",
+			},
+			want: &marker.Marker{
+				Name:           "entrypoint.sh",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./entrypoint.sh",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
 				},
 				Indentation: &marker.Indentation{
 					Mode:              marker.AlignIndentation,
-					MarkerIndentation: 4,
+					MarkerIndentation: 2,
+				},
+				BlockScalar: &marker.BlockScalar{
+					Key:         "entrypoint.sh",
+					Indentation: 2,
 				},
 			},
 		},
-		"Exporter with indent keep": {
+		"Literal wrap": {
 			input: &marker.RawMarker{
-				Name:                 "simple-marker",
+				Name:                 "entrypoint.sh",
 				IsBeginFound:         true,
 				IsEndFound:           true,
 				LineToInsertAt:       3,
-				Options:              "from: ./abc.yaml#[from-exporter-marker] indent: keep",
-				PrecedingIndentation: "    ",
+				PrecedingIndentation: "  ",
+				Options:              "from: ./entrypoint.sh#1~ wrap: literal",
+			},
+			want: &marker.Marker{
+				Name:           "entrypoint.sh",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./entrypoint.sh",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:     marker.LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				Wrap: &marker.Wrap{
+					LanguageType: "literal",
+				},
+				BlockScalar: &marker.BlockScalar{
+					Key:         "entrypoint.sh",
+					Indentation: 2,
+				},
+			},
+		},
+		"Max lines": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        `from: ./abc.go#6~ max-lines: 20 "// ..."`,
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.yaml",
+					File: "./abc.go",
 				},
 				ImportLogic: marker.ImportLogic{
-					Type:           marker.ExporterMarker,
-					ExporterMarker: "from-exporter-marker",
+					Type:     marker.LineRange,
+					LineFrom: 6,
+					LineTo:   math.MaxInt32,
 				},
-				Indentation: &marker.Indentation{
-					Mode: marker.KeepIndentation,
+				MaxLines: &marker.MaxLines{
+					Count:     20,
+					Indicator: "// ...",
 				},
 			},
 		},
-		"Quote": {
+		"Max lines with link": {
 			input: &marker.RawMarker{
 				Name:           "simple-marker",
 				IsBeginFound:   true,
 				IsEndFound:     true,
 				LineToInsertAt: 3,
-				Options:        "from: ./abc.md#3~5 style: quote abc", // "abc" is used as language, but quote simply ignores this
+				Options:        "from: ./abc.go#6~ max-lines: 20 link",
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
 				LineToInsertAt: 3,
 				ImportTargetFile: marker.ImportTargetFile{
 					Type: marker.PathBased,
-					File: "./abc.md",
+					File: "./abc.go",
 				},
 				ImportLogic: marker.ImportLogic{
 					Type:     marker.LineRange,
-					LineFrom: 3,
-					LineTo:   5,
+					LineFrom: 6,
+					LineTo:   math.MaxInt32,
 				},
-				Indentation: nil,
-				ImportStyle: &marker.ImportStyle{
-					Mode: marker.Quote,
+				MaxLines: &marker.MaxLines{
+					Count: 20,
+					Link:  true,
 				},
 			},
 		},
-		"Verbatim ": {
+		"Vars": {
 			input: &marker.RawMarker{
 				Name:           "simple-marker",
 				IsBeginFound:   true,
 				IsEndFound:     true,
 				LineToInsertAt: 3,
-				Options:        "from: ./abc.md#3~5 style: verbatim some-lang",
+				Options:        "from: ./abc.yaml#[service] vars: name=frontend,port=8080,empty=",
+			},
+			want: &marker.Marker{
+				Name:           "simple-marker",
+				LineToInsertAt: 3,
+				ImportTargetFile: marker.ImportTargetFile{
+					Type: marker.PathBased,
+					File: "./abc.yaml",
+				},
+				ImportLogic: marker.ImportLogic{
+					Type:           marker.ExporterMarker,
+					ExporterMarker: "service",
+				},
+				Vars: &marker.Vars{
+					Values: map[string]string{
+						"name":  "frontend",
+						"port":  "8080",
+						"empty": "",
+					},
+				},
+			},
+		},
+		"Replace multiple times": {
+			input: &marker.RawMarker{
+				Name:           "simple-marker",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 3,
+				Options:        `from: ./abc.md#3~5 replace: /example\.com/my-site.com/ replace: /image:(\S+)\/app:\S+/image: $1\/app:v1.2.3/`,
 			},
 			want: &marker.Marker{
 				Name:           "simple-marker",
@@ -249,9 +1320,9 @@ func TestNewMarker(t *testing.T) {
 					LineFrom: 3,
 					LineTo:   5,
 				},
-				Indentation: nil,
-				Wrap: &marker.Wrap{
-					LanguageType: "some-lang",
+				Replaces: []marker.Replace{
+					{Pattern: `example\.com`, Replacement: "my-site.com"},
+					{Pattern: `image:(\S+)\/app:\S+`, Replacement: "image: $1/app:v1.2.3"},
 				},
 			},
 		},
@@ -307,6 +1378,36 @@ func TestNewMarkerFail(t *testing.T) {
 			},
 			wantErr: marker.ErrInvalidSyntax,
 		},
+		"Negative single line": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#-1",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Negative line in line array": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#5,-5",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Negative line range in line array": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#1,-3~-1",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
 		"Invalid input for line numbers": {
 			input: &marker.RawMarker{
 				Name:           "dummy",
@@ -347,6 +1448,76 @@ func TestNewMarkerFail(t *testing.T) {
 			},
 			wantErr: marker.ErrInvalidSyntax,
 		},
+		"Invalid input for line range: anchor with invalid regular expression": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#/abc[/~5",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Invalid input for line range: anchor with unknown flag": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#/abc/z~5",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Invalid input for exclusion: missing selector": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#!5",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Invalid input for headings base level": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#3~5 headings: base 7",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Invalid input for vars without value": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.yaml#[service] vars: name=frontend,port",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Invalid input for replace pattern": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.md#3~5 replace: /(abc/xyz/",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
+		"Invalid input for max lines": {
+			input: &marker.RawMarker{
+				Name:           "dummy",
+				IsBeginFound:   true,
+				IsEndFound:     true,
+				LineToInsertAt: 1,
+				Options:        "from: ./abc.go#6~ max-lines: 0",
+			},
+			wantErr: marker.ErrInvalidSyntax,
+		},
 		"Invalid input for filename": {
 			input: &marker.RawMarker{
 				Name:           "dummy",
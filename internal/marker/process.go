@@ -2,7 +2,7 @@ package marker
 
 import (
 	"bufio"
-	"errors"
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,8 +10,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-
-	"github.com/upsidr/importer/internal/regexpplus"
 )
 
 // ProcessMarkerData processes the marker data to generate the byte array of
@@ -25,6 +23,10 @@ func (m *Marker) ProcessMarkerData(importingFilePath string) ([]byte, error) {
 	targetFile := m.ImportTargetFile.File
 	switch m.ImportTargetFile.Type {
 	case PathBased:
+		if targetFile == "" {
+			return nil, fmt.Errorf("%w", ErrNoFileInput)
+		}
+
 		// Make sure the files are read based on the relative path
 		dir := filepath.Dir(importingFilePath)
 		targetPath := filepath.Join(dir, targetFile)
@@ -55,204 +57,297 @@ func (m *Marker) ProcessMarkerData(importingFilePath string) ([]byte, error) {
 		return nil, fmt.Errorf("%w", ErrNoFileInput)
 	}
 
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w, %v", ErrGetMarkerTarget, err)
+	}
+
 	fileType := filepath.Ext(importingFilePath)
+
+	sections, err := m.selectSections(content, fileType)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Vars != nil {
+		sections = substituteVars(sections, m.Vars)
+	}
+	if len(m.Replaces) > 0 {
+		sections = replaceContent(sections, m.Replaces)
+	}
+	if m.Strip != nil {
+		sections = stripComments(sections, m.ImportTargetFile.File, m.Strip.Trailing)
+	}
+	if m.Convert != nil {
+		sections, err = convertFormat(sections, m.Convert.Format)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.Trim {
+		sections = trimBlankLines(sections)
+	}
+	if m.Squeeze {
+		sections = squeezeBlankLines(sections)
+	}
+
+	full := sections
+	truncated := false
+	if m.MaxLines != nil {
+		sections, truncated = m.truncateSections(sections, fileType)
+	}
+
+	char := m.Indentation.indentChar(sections, fileType)
+	if m.shouldConvertIndentation(fileType) {
+		sections = convertIndentation(sections, char)
+	}
+	if m.Indentation != nil && m.Indentation.Mode == DedentIndentation {
+		sections = dedentSections(sections, m.Indentation.Length, char)
+	}
+
+	var result []byte
 	switch fileType {
 	case ".md":
-		return m.processSingleMarkerMarkdown(file)
+		result, err = m.processSingleMarkerMarkdown(sections)
 	case ".yaml", ".yml":
-		return m.processSingleMarkerYAML(file)
+		result, err = m.processSingleMarkerYAML(sections)
 	default:
-		return m.processSingleMarkerOther(file)
+		result, err = m.processSingleMarkerOther(sections)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case truncated && m.MaxLines.Link:
+		result = m.addFullFileLink(result, full, fileType)
+	case truncated && m.embedsBlockScalar(fileType):
+		result = m.addBlockScalarIndicator(result)
+	}
+
+	return result, nil
 }
 
 const br = byte('\n')
 
-func (m *Marker) processSingleMarkerMarkdown(file io.Reader) ([]byte, error) {
-	result := []byte{}
-
-	withinExportMarker := false
-	currentLine := 0
+// resolve converts the import logic which depends on the import target file
+// content into simple line range.
+func (l ImportLogic) resolve(content []byte) (ImportLogic, error) {
+	switch {
+	case l.Type == GoDeclaration:
+		from, to, err := findGoDeclaration(content, l.GoDecl)
+		if err != nil {
+			return l, err
+		}
+		return ImportLogic{Type: LineRange, LineFrom: from, LineTo: to}, nil
+
+	// Negative line numbers are counted from the end of file. This works
+	// similarly to slice handling in some languages: "-10~" means the last 10
+	// lines, and "~-2" means all but the last 2 lines.
+	case l.LineFrom < 0 || l.LineTo < 0:
+		total := countLines(content)
+		if l.LineFrom < 0 {
+			l.LineFrom = total + l.LineFrom + 1
+		}
+		if l.LineTo < 0 {
+			l.LineTo = total + l.LineTo
+		}
+	}
 
-	if m.Wrap != nil {
-		result = append(result, []byte("```"+m.Wrap.LanguageType)...)
-		result = append(result, br)
+	if l.LineFromAnchor != nil || l.LineToAnchor != nil {
+		from, to, err := resolveAnchors(content, l)
+		if err != nil {
+			return l, err
+		}
+		return ImportLogic{Type: LineRange, LineFrom: from, LineTo: to}, nil
 	}
 
-	scanner := bufio.NewScanner(file)
+	return l, nil
+}
+
+func countLines(content []byte) int {
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
 	for scanner.Scan() {
-		currentLine++
+		count++
+	}
+	return count
+}
 
-		dataToWrite := append(scanner.Bytes(), br)
-		if m.ImportStyle != nil && m.ImportStyle.Mode == Quote {
-			dataToWrite = append([]byte("> "), dataToWrite...)
-		}
+func (m *Marker) processSingleMarkerMarkdown(sections []section) ([]byte, error) {
+	result := []byte{}
 
-		// Find Exporter Marker
-		var exporter string
-		targetFileType := filepath.Ext(m.ImportTargetFile.File)
-		switch targetFileType {
-		case ".md":
-			exporter = ExporterMarkerMarkdown
-		case ".yaml", ".yml":
-			exporter = ExporterMarkerYAML
-		default:
-			exporter = ExporterMarkerMarkdown
+	details := m.ImportStyle != nil && m.ImportStyle.Mode == Details
+	if details {
+		result = append(result, []byte("<details>")...)
+		result = append(result, br)
+		if m.ImportStyle.Summary != "" {
+			result = append(result, []byte("<summary>"+m.ImportStyle.Summary+"</summary>")...)
+			result = append(result, br)
 		}
+		// Blank line is required for Markdown to be rendered within HTML tag.
+		result = append(result, br)
+	}
 
-		matches, err := regexpplus.MapWithNamedSubgroups(scanner.Text(), exporter)
-		if err != nil && !errors.Is(err, regexpplus.ErrNoMatch) {
-			panic(err) // Unknown error, should not happen
-		}
+	lines := []line{}
+	for _, s := range sections {
+		lines = append(lines, s.lines...)
+	}
 
-		if len(matches) != 0 {
-			if exporterName, found := matches["export_marker_name"]; found &&
-				exporterName == m.ImportLogic.ExporterMarker {
-				withinExportMarker = true
-			}
-			if exporterCondition, found := matches["exporter_marker_condition"]; found &&
-				exporterCondition == "end" {
-				withinExportMarker = false
+	if m.shouldRewriteLinks() {
+		lines = rewriteLinks(lines, m.ImportTargetFile.File)
+	}
+
+	if m.Headings != nil {
+		lines = shiftHeadings(lines, m.Headings)
+	}
+
+	if m.ImportStyle != nil {
+		switch m.ImportStyle.Mode {
+		case UnorderedList, OrderedList, TaskList:
+			lines = applyListStyle(lines, m.ImportStyle.Mode)
+		case Table:
+			var err error
+			lines, err = applyTableStyle(lines, m.ImportTargetFile.File)
+			if err != nil {
+				return nil, err
 			}
-			continue
+		case EscapeHTML:
+			lines = escapeHTML(lines)
+		case EscapeMarkdownTable:
+			lines = escapeMarkdownTable(lines)
 		}
+	}
 
-		// Handle Exporter Marker imports
-		if withinExportMarker {
-			result = append(result, dataToWrite...)
-			continue
-		}
+	if m.LineNumbers != nil && m.LineNumbers.Mode == PrefixLineNumbers {
+		lines = prefixLineNumbers(lines)
+	}
 
-		// Handle line number imports
-		if currentLine >= m.ImportLogic.LineFrom &&
-			currentLine <= m.ImportLogic.LineTo {
-			result = append(result, dataToWrite...)
-			continue
+	fence := ""
+	if m.Wrap != nil {
+		fence = codeFenceFor(lines)
+		lang := m.Wrap.LanguageType
+		if lang == "" {
+			lang = fenceLanguage(m.ImportTargetFile.File)
 		}
-		for _, l := range m.ImportLogic.Lines {
-			if currentLine == l {
-				result = append(result, dataToWrite...)
-				continue
-			}
+		if m.LineNumbers != nil {
+			lang += m.LineNumbers.fenceAttributes(lines)
 		}
+		result = append(result, []byte(fence+lang)...)
+		result = append(result, br)
+	}
+
+	for _, l := range lines {
+		dataToWrite := append([]byte(l.text), br)
+		if m.ImportStyle != nil && m.ImportStyle.Mode == Quote {
+			dataToWrite = append([]byte("> "), dataToWrite...)
+		}
+		result = append(result, dataToWrite...)
 	}
 
 	if m.Wrap != nil {
-		result = append(result, []byte("```")...)
+		result = append(result, []byte(fence)...)
 		result = append(result, br)
 	}
 
+	if details {
+		result = append(result, br)
+		result = append(result, []byte("</details>")...)
+		result = append(result, br)
+	}
+
+	if m.ImportStyle != nil && m.ImportStyle.Mode == Comment {
+		result = htmlComment.commentOut(result)
+	}
+
+	if m.Caption != nil {
+		label, link := m.captionSource(sections)
+		result = m.addCaption(result, fmt.Sprintf("Source: [%s](%s)", label, link), true)
+	}
+
 	return result, nil
 }
 
-func (m *Marker) processSingleMarkerYAML(file io.Reader) ([]byte, error) {
+// shouldConvertIndentation checks whether the indentation of the imported
+// content needs to be converted. Unless the character is specified, YAML
+// importing file converts tabs to spaces when adjusting indentation, as YAML
+// does not allow tabs for indentation. Block scalar content is kept as is.
+func (m *Marker) shouldConvertIndentation(fileType string) bool {
+	if m.Indentation == nil {
+		return false
+	}
+	if m.Indentation.Char != 0 {
+		return true
+	}
+	if fileType != ".yaml" && fileType != ".yml" {
+		return false
+	}
+	return m.BlockScalar == nil && m.Indentation.Mode != KeepIndentation
+}
+
+// shouldRewriteLinks checks whether relative links in the imported content
+// need to be updated. Links can be only updated for local files, and unless
+// specified otherwise, only Markdown import target is updated.
+func (m *Marker) shouldRewriteLinks() bool {
+	if m.ImportTargetFile.Type != PathBased {
+		return false
+	}
+	if m.Links != nil {
+		return m.Links.Mode == RewriteLinks
+	}
+	return filepath.Ext(m.ImportTargetFile.File) == ".md"
+}
+
+func (m *Marker) processSingleMarkerYAML(sections []section) ([]byte, error) {
 	result := []byte{}
 
-	isNested := false
-	nestedUnder := ""
-	exporterMarkerIndentation := 0
-	currentLine := 0
+	char := m.Indentation.indentChar(sections, ".yaml")
+	for _, s := range sections {
+		// Indentation is adjusted based on Exporter Marker indentation. When
+		// Exporter Marker is not used, the first imported line is used as
+		// the base indentation instead.
+		baseIndentation := len(s.exporterIndentation)
+		if !s.fromExporter {
+			baseIndentation = firstLineIndentation(s.lines)
+		}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		currentLine++
-
-		lineString := scanner.Text()
-		lineData := scanner.Bytes()
-
-		switch {
-		// Handle line number range
-		case m.ImportLogic.LineFrom > 0:
-			if currentLine >= m.ImportLogic.LineFrom &&
-				currentLine <= m.ImportLogic.LineTo {
-				lineData = append(lineData, br)
-				result = append(result, lineData...)
-				continue
-			}
+		for _, l := range s.lines {
+			lineData := adjustIndentation([]byte(l.text), baseIndentation, m.Indentation, char)
+			result = append(result, lineData...)
+		}
+	}
 
-		// Handle line number slice
-		case len(m.ImportLogic.Lines) > 0:
-			for _, l := range m.ImportLogic.Lines {
-				if currentLine == l {
-					lineData = append(lineData, br)
-					result = append(result, lineData...)
-					continue
-				}
-			}
+	// Block scalar handles the indentation on its own, and thus indentation
+	// option is not used.
+	if m.BlockScalar != nil {
+		result = m.BlockScalar.render(sections)
+	}
 
-		// Handle ExporterMarker
-		case m.ImportLogic.ExporterMarker != "":
-			// Find Exporter Marker
-			matches, err := regexpplus.MapWithNamedSubgroups(lineString, ExporterMarkerYAML)
-			if errors.Is(err, regexpplus.ErrNoMatch) {
-				// This line is not Exporter Marker. If there has been some
-				// marker found already, append the line and continue
-				if isNested {
-					lineData = adjustIndentation(lineData, exporterMarkerIndentation, m.Indentation)
-					result = append(result, lineData...)
-				}
-				continue
-			}
-			if err != nil {
-				panic(err) // Unknown error, should not happen
-			}
+	if m.ImportStyle != nil && m.ImportStyle.Mode == Comment {
+		result = hashComment.commentOut(result)
+	}
 
-			if exporterName, found := matches["export_marker_name"]; found {
-				// Ignore unrelated marker
-				if exporterName != m.ImportLogic.ExporterMarker || exporterName == "" {
-					continue
-				}
-
-				if x, found := matches["export_marker_indent"]; found {
-					indent := len(x) - len(strings.TrimLeft(x, " "))
-					exporterMarkerIndentation = indent
-				}
-
-				if exporterCondition, found := matches["exporter_marker_condition"]; found {
-					if exporterName == nestedUnder && exporterCondition == "end" {
-						isNested = false
-						exporterMarkerIndentation = 0
-						continue
-					}
-				}
-				nestedUnder = exporterName
-				isNested = true
-			}
-		}
+	// Caption is added as a comment, with the same indentation as the first
+	// imported line so that it stays within the YAML tree.
+	if m.Caption != nil {
+		_, link := m.captionSource(sections)
+		indent := result[:len(result)-len(bytes.TrimLeft(result, " \t"))]
+		result = m.addCaption(result, fmt.Sprintf("%s# Source: %s", indent, link), false)
 	}
+
 	return result, nil
 }
 
-func (m *Marker) processSingleMarkerOther(file io.Reader) ([]byte, error) {
+func (m *Marker) processSingleMarkerOther(sections []section) ([]byte, error) {
 	result := []byte{}
 
-	currentLine := 0
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		currentLine++
-
-		// There is no export marker handling for unspecified file type. This
-		// is because it is impossible to find what comment format is allowed
-		// in the target file.
-		// If there are specific use cases, it would need to be implemented
-		// separately.
-
-		// Handle line number imports
-		if currentLine >= m.ImportLogic.LineFrom &&
-			currentLine <= m.ImportLogic.LineTo {
-			result = append(result, scanner.Bytes()...)
+	for _, s := range sections {
+		for _, l := range s.lines {
+			result = append(result, []byte(l.text)...)
 			result = append(result, br)
-			continue
-		}
-		for _, l := range m.ImportLogic.Lines {
-			if currentLine == l {
-				result = append(result, scanner.Bytes()...)
-				result = append(result, br)
-				continue
-			}
 		}
 	}
+
 	return result, nil
 }
 
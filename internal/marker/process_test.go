@@ -2,6 +2,8 @@ package marker
 
 import (
 	"errors"
+	"go/token"
+	"math"
 	"os"
 	"testing"
 
@@ -35,6 +37,59 @@ func TestProcessSingleMarker(t *testing.T) {
 			want: []byte(`This is test data.
 他言語サポートのためのテスト文章。
 🍸 Emojis 🍷 Supported 🍺
+`),
+		},
+		"markdown: last lines": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/note.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: -2,
+					LineTo:   math.MaxInt32,
+				},
+			},
+			want: []byte(`他言語サポートのためのテスト文章。
+🍸 Emojis 🍷 Supported 🍺
+`),
+		},
+		"markdown: all but last line": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/note.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 0,
+					LineTo:   -1,
+				},
+			},
+			want: []byte(`This is test data.
+他言語サポートのためのテスト文章。
+`),
+		},
+		"markdown: more lines than file from end": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/note.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: -10,
+					LineTo:   -2,
+				},
+			},
+			want: []byte(`This is test data.
 `),
 		},
 		"markdown: comma separated lines": {
@@ -67,276 +122,2001 @@ func TestProcessSingleMarker(t *testing.T) {
 					ExporterMarker: "test_exporter",
 				},
 			},
-			want: []byte(`
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
-🚀🚀🚀🚀🚀🚀🚀🚀
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
+			want: []byte(`
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+🚀🚀🚀🚀🚀🚀🚀🚀
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+
+`),
+		},
+		"markdown: exporter marker with quote": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-with-exporter.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "test_exporter",
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Quote,
+				},
+			},
+			want: []byte(`> 
+> ✨✨✨✨✨✨✨✨
+> ✨✨✨✨✨✨✨✨
+> ✨✨✨✨✨✨✨✨
+> 🚀🚀🚀🚀🚀🚀🚀🚀
+> ✨✨✨✨✨✨✨✨
+> ✨✨✨✨✨✨✨✨
+> ✨✨✨✨✨✨✨✨
+> 
+`),
+		},
+		"markdown: exporter marker with verbatim": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-with-exporter.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "test_exporter",
+				},
+				Wrap: &Wrap{
+					LanguageType: "some-lang",
+				},
+			},
+			want: []byte("```" + `some-lang
+
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+🚀🚀🚀🚀🚀🚀🚀🚀
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+✨✨✨✨✨✨✨✨
+
+` + "```" + `
+`),
+		},
+		"markdown: multiple exporter markers with separator": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-color-svc.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type: MultipleSelectors,
+					Selectors: []ImportLogic{
+						{Type: ExporterMarker, ExporterMarker: "disable-all-colours"},
+						{Type: ExporterMarker, ExporterMarker: "basic-envs"},
+					},
+				},
+				Separator: &Separator{
+					Line: "  # ...",
+				},
+				Wrap: &Wrap{
+					LanguageType: "yaml",
+				},
+			},
+			want: []byte("```" + `yaml
+  - name: DISABLE_RED
+    value: "true"
+  - name: DISABLE_GREEN
+    value: "true"
+  - name: DISABLE_BLUE
+    value: "true"
+  - name: DISABLE_YELLOW
+    value: "true"
+  # ...
+  - name: ENABLE_DELAY
+    value: "true"
+  - name: DELAY_DURATION_MILLISECOND
+    value: "500"
+  - name: ENABLE_CORS
+    value: "true"
+` + "```" + `
+`),
+		},
+		"markdown: exporter marker and line range": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-description.md",
+				},
+				ImportLogic: ImportLogic{
+					Type: MultipleSelectors,
+					Selectors: []ImportLogic{
+						{Type: LineRange, LineFrom: 1, LineTo: 1},
+						{Type: ExporterMarker, ExporterMarker: "for-demo"},
+					},
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Quote,
+				},
+			},
+			want: []byte(`> # Descriptions
+> This demonstrates how a markdown can import other file content.
+> 
+> Importer is a CLI tool to read and process Importer and Exporter markers.  
+> This can be easily integrated into CI/CD and automation setup.
+`),
+		},
+		"markdown: unordered list": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/steps.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: UnorderedList,
+				},
+			},
+			want: []byte(`- Prepare environment
+  - Install Go
+  - Install Importer
+    - go install github.com/upsidr/importer/cmd/importer@latest
+- Run Importer
+  - Preview the change
+  - Update the file
+`),
+		},
+		"markdown: ordered list": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/steps.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: OrderedList,
+				},
+			},
+			want: []byte(`1. Prepare environment
+    1. Install Go
+    2. Install Importer
+        1. go install github.com/upsidr/importer/cmd/importer@latest
+2. Run Importer
+    1. Preview the change
+    2. Update the file
+`),
+		},
+		"markdown: task list": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/steps.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type:  CommaSeparatedLines,
+					Lines: []int{6, 7, 8},
+				},
+				ImportStyle: &ImportStyle{
+					Mode: TaskList,
+				},
+			},
+			want: []byte(`- [ ] Run Importer
+  - [ ] Preview the change
+  - [ ] Update the file
+`),
+		},
+		"markdown: headings with relative shift": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-headings.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				Headings: &Headings{
+					Mode:  RelativeHeadings,
+					Level: 1,
+				},
+			},
+			want: []byte(`## Usage Guide
+
+Some introduction.
+
+### Install
+
+` + "```" + `bash
+# This is a comment, not a heading
+go install github.com/upsidr/importer/cmd/importer@latest
+` + "```" + `
+
+### Configuration
+
+#### Options
+`),
+		},
+		"markdown: headings with base level": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-headings.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 6,
+					LineTo:   math.MaxInt32,
+				},
+				Headings: &Headings{
+					Mode:  BaseHeadings,
+					Level: 5,
+				},
+			},
+			want: []byte(`##### Install
+
+` + "```" + `bash
+# This is a comment, not a heading
+go install github.com/upsidr/importer/cmd/importer@latest
+` + "```" + `
+
+##### Configuration
+
+###### Options
+`),
+		},
+		"markdown: relative links rewritten": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/nested/snippet-links.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 3,
+					LineTo:   math.MaxInt32,
+				},
+			},
+			want: []byte(`![Diagram](../../testdata/markdown/nested/images/diagram.png)
+
+See [the guide](../../testdata/markdown/guide.md#install), [the website](https://example.com), and [this section](#links).
+
+[![Badge](../../testdata/markdown/nested/badge.svg)](../../testdata/markdown/nested/status/)
+
+` + "```" + `markdown
+[Kept as is](./in-code-block.md)
+` + "```" + `
+
+[ref]: ../../testdata/markdown/nested/reference.md "Reference"
+`),
+		},
+		"markdown: relative links kept": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/nested/snippet-links.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:  CommaSeparatedLines,
+					Lines: []int{3},
+				},
+				Links: &Links{
+					Mode: KeepLinks,
+				},
+			},
+			want: []byte(`![Diagram](./images/diagram.png)
+`),
+		},
+		"markdown: dedent": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 21,
+					LineTo:   24,
+				},
+				Indentation: &Indentation{
+					Mode: DedentIndentation,
+				},
+				Wrap: &Wrap{
+					LanguageType: "go",
+				},
+			},
+			want: []byte("```" + `go
+if name == "" {
+	name = DefaultName
+}
+return &Greeter{Name: name}
+` + "```" + `
+`),
+		},
+		"markdown: dedent with tabs converted to spaces": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 21,
+					LineTo:   24,
+				},
+				Indentation: &Indentation{
+					Mode:   DedentIndentation,
+					Length: 2,
+					Char:   SpaceIndent,
+				},
+			},
+			want: []byte(`  if name == "" {
+      name = DefaultName
+  }
+  return &Greeter{Name: name}
+`),
+		},
+		"other: dedent with detected tabs": {
+			callerFile: "./some_unknown_file_type",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 21,
+					LineTo:   24,
+				},
+				Indentation: &Indentation{
+					Mode:   DedentIndentation,
+					Length: 1,
+				},
+			},
+			want: []byte("\tif name == \"\" {\n\t\tname = DefaultName\n\t}\n\treturn &Greeter{Name: name}\n"),
+		},
+		"markdown: details with wrap": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   3,
+				},
+				ImportStyle: &ImportStyle{
+					Mode:    Details,
+					Summary: "Full configuration",
+				},
+				Wrap: &Wrap{
+					LanguageType: "yaml",
+				},
+			},
+			want: []byte(`<details>
+<summary>Full configuration</summary>
+
+` + "```" + `yaml
+a:
+  b:
+    c:
+` + "```" + `
+
+</details>
+`),
+		},
+		"markdown: details without summary": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   2,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Details,
+				},
+			},
+			want: []byte(`<details>
+
+a:
+  b:
+
+</details>
+`),
+		},
+		"markdown: wrap with language from target": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:   GoDeclaration,
+					GoDecl: &GoDecl{Kind: token.CONST, Name: "DefaultName"},
+				},
+				Wrap: &Wrap{},
+			},
+			want: []byte("```" + `go
+// DefaultName is used when no name is given.
+const DefaultName = "importer"
+` + "```" + `
+`),
+		},
+		"markdown: wrap content with code fence": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-headings.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 6,
+					LineTo:   11,
+				},
+				Wrap: &Wrap{},
+			},
+			want: []byte("````" + `markdown
+## Install
+
+` + "```" + `bash
+# This is a comment, not a heading
+go install github.com/upsidr/importer/cmd/importer@latest
+` + "```" + `
+` + "````" + `
+`),
+		},
+		"markdown: caption above wrapped code": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:   GoDeclaration,
+					GoDecl: &GoDecl{Kind: token.CONST, Name: "DefaultName"},
+				},
+				Wrap:    &Wrap{},
+				Caption: &Caption{Position: CaptionAbove},
+			},
+			want: []byte(`Source: [snippet-declarations.go#L11-L12](../../testdata/go/snippet-declarations.go#L11-L12)
+
+` + "```" + `go
+// DefaultName is used when no name is given.
+const DefaultName = "importer"
+` + "```" + `
+`),
+		},
+		"markdown: line number prefix": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 9,
+					LineTo:   12,
+				},
+				Wrap:        &Wrap{},
+				LineNumbers: &LineNumbers{Mode: PrefixLineNumbers},
+			},
+			want: []byte("```" + `go
+ 9  )
+10
+11  // DefaultName is used when no name is given.
+12  const DefaultName = "importer"
+` + "```" + `
+`),
+		},
+		"markdown: line numbers for hugo": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:   GoDeclaration,
+					GoDecl: &GoDecl{Kind: token.CONST, Name: "DefaultName", WithoutDoc: true},
+				},
+				Wrap:        &Wrap{},
+				LineNumbers: &LineNumbers{Mode: HugoLineNumbers},
+			},
+			want: []byte("```" + `go {linenos=true,linenostart=12}
+const DefaultName = "importer"
+` + "```" + `
+`),
+		},
+		"markdown: line numbers for mdx": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:   GoDeclaration,
+					GoDecl: &GoDecl{Kind: token.CONST, Name: "DefaultName", WithoutDoc: true},
+				},
+				Wrap: &Wrap{
+					LanguageType: "golang",
+				},
+				LineNumbers: &LineNumbers{Mode: MDXLineNumbers},
+			},
+			want: []byte("```" + `golang showLineNumbers{12}
+const DefaultName = "importer"
+` + "```" + `
+`),
+		},
+		"markdown: trim and squeeze blank lines": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-blank-lines.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "padded",
+				},
+				Trim:    true,
+				Squeeze: true,
+			},
+			want: []byte(`First paragraph.
+
+Second paragraph.
+`),
+		},
+		"markdown: csv as table": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/compatibility.csv",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Table,
+				},
+			},
+			want: []byte(`| Platform | Architecture   | Supported    |
+| -------- | -------------- | ------------ |
+| Linux    | amd64, arm64   | Yes          |
+| macOS    | amd64 \| arm64 | Yes          |
+| Windows  | amd64          | Experimental |
+`),
+		},
+		"markdown: tsv as table with selected rows": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/compatibility.tsv",
+				},
+				ImportLogic: ImportLogic{
+					Type:  CommaSeparatedLines,
+					Lines: []int{1, 3},
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Table,
+				},
+			},
+			want: []byte(`| Platform | Supported |
+| -------- | --------- |
+| macOS    | Yes       |
+`),
+		},
+		"markdown: csv as table with header not selected": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/compatibility.csv",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 3,
+					LineTo:   5,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Table,
+				},
+			},
+			want: []byte(`| Platform | Architecture   | Supported    |
+| -------- | -------------- | ------------ |
+| macOS    | amd64 \| arm64 | Yes          |
+| Windows  | amd64          | Experimental |
+`),
+		},
+		"markdown: convert yaml to json": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-resource.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "min-resource",
+				},
+				Wrap: &Wrap{
+					LanguageType: "json",
+				},
+				Convert: &Convert{Format: ConvertJSON},
+			},
+			want: []byte("```" + `json
+{
+  "resources": {
+    "requests": {
+      "cpu": "10m",
+      "memory": "10Mi"
+    },
+    "limits": {
+      "cpu": "30m",
+      "memory": "30Mi"
+    }
+  }
+}
+` + "```" + `
+`),
+		},
+		"markdown: escape html": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 21,
+					LineTo:   24,
+				},
+				Indentation: &Indentation{
+					Mode: DedentIndentation,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: EscapeHTML,
+				},
+			},
+			want: []byte(`if name == &#34;&#34; {
+	name = DefaultName
+}
+return &amp;Greeter{Name: name}
+`),
+		},
+		"markdown: escape markdown table": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/compatibility.csv",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 2,
+					LineTo:   3,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: EscapeMarkdownTable,
+				},
+			},
+			want: []byte(`Linux,"amd64, arm64",Yes<br>macOS,amd64 \| arm64,Yes
+`),
+		},
+		"markdown: comment style": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   2,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Comment,
+				},
+			},
+			want: []byte(`<!--
+a:
+  b:
+-->
+`),
+		},
+		"markdown: comment style with html comment in data": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-with-html-comment.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   3,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Comment,
+				},
+			},
+			want: []byte(`<!--
+Intro
+&lt;!-- note --&gt;
+Secret: **live**
+-->
+`),
+		},
+		"markdown: literal wrap as code block": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   2,
+				},
+				Wrap: &Wrap{
+					LanguageType: "literal",
+				},
+				BlockScalar: &BlockScalar{
+					Key: "snippet",
+				},
+			},
+			want: []byte("```" + `literal
+a:
+  b:
+` + "```" + `
+`),
+		},
+		"markdown: max lines with custom indicator": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/entrypoint.sh",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				Wrap:     &Wrap{},
+				MaxLines: &MaxLines{Count: 2, Indicator: "# …"},
+			},
+			want: []byte("```" + `sh
+#!/bin/sh
+set -eu
+# …
+` + "```" + `
+`),
+		},
+		"markdown: max lines with link": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/entrypoint.sh",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				Wrap:     &Wrap{},
+				MaxLines: &MaxLines{Count: 2, Link: true},
+			},
+			want: []byte("```" + `sh
+#!/bin/sh
+set -eu
+` + "```" + `
+
+[See full file](../../testdata/other/entrypoint.sh#L1-L5)
+`),
+		},
+		"yaml: line range": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 2,
+					LineTo:   5,
+				},
+				Indentation: nil,
+			},
+			want: []byte(`  b:
+    c:
+      d:
+        e:
+`),
+		},
+		"yaml: line range with URL, github.com - this may fail if GitHub is down": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: URLBased,
+					File: "https://github.com/upsidr/importer/blob/main/testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 2,
+					LineTo:   5,
+				},
+				Indentation: nil,
+			},
+			want: []byte(`  b:
+    c:
+      d:
+        e:
+`),
+		},
+		"yaml: line range with URL, raw.githubusercontent.com": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: URLBased,
+					File: "https://raw.githubusercontent.com/upsidr/importer/main/testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 2,
+					LineTo:   5,
+				},
+				Indentation: nil,
+			},
+			want: []byte(`  b:
+    c:
+      d:
+        e:
+`),
+		},
+		"yaml: comma separated lines": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:  LineRange,
+					Lines: []int{1, 2, 4},
+				},
+				Indentation: nil,
+			},
+			want: []byte(`a:
+  b:
+      d:
+`),
+		},
+		"yaml: line range with absolute indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 4,
+					LineTo:   6,
+				},
+				Indentation: &Indentation{
+					Mode:   AbsoluteIndentation,
+					Length: 2,
+				},
+			},
+			want: []byte(`  d:
+    e:
+      f:
+`),
+		},
+		"yaml: comma separated lines with align indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-color-svc.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:  CommaSeparatedLines,
+					Lines: []int{22, 23},
+				},
+				Indentation: &Indentation{
+					Mode:              AlignIndentation,
+					MarkerIndentation: 6,
+				},
+			},
+			want: []byte(`      - name: ENABLE_DELAY
+        value: "true"
+`),
+		},
+		"yaml: line range with extra indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   2,
+				},
+				Indentation: &Indentation{
+					Mode:   ExtraIndentation,
+					Length: 4,
+				},
+			},
+			want: []byte(`    a:
+      b:
+`),
+		},
+		"yaml: exporter marker": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "long-tree",
+				},
+				Indentation: nil,
+			},
+			want: []byte(`a:
+  b:
+    c:
+      d:
+        e:
+          f:
+            g:
+              h:
+                i:
+                  j:
+                    k: {}
+`),
+		},
+		"yaml: exporter marker with absolute indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "metadata-only",
+				},
+				Indentation: &Indentation{
+					Mode:   AbsoluteIndentation,
+					Length: 30,
+				},
+			},
+			want: []byte(`                              metadata:
+                                name: sample-data
+                                namespace: sample-namespace
+`),
+		},
+		"yaml: exporter marker with absolute indentation, with zero indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "metadata-only",
+				},
+				Indentation: &Indentation{
+					Mode:   AbsoluteIndentation,
+					Length: 0,
+				},
+			},
+			want: []byte(`metadata:
+  name: sample-data
+  namespace: sample-namespace
+`),
+		},
+		"yaml: exporter marker with extra indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "sample-nested",
+				},
+				Indentation: &Indentation{
+					Mode:   ExtraIndentation,
+					Length: 2,
+				},
+			},
+			want: []byte(`    nested:
+      more:
+        data:
+          sample: This is a sample data
+        metadata:
+          name: sample-data
+          namespace: sample-namespace
+`),
+		},
+		"yaml: exporter marker with align indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "sample-nested",
+				},
+				Indentation: &Indentation{
+					Mode:              AlignIndentation,
+					MarkerIndentation: 10,
+				},
+			},
+			want: []byte(`          nested:
+            more:
+              data:
+                sample: This is a sample data
+              metadata:
+                name: sample-data
+                namespace: sample-namespace
+`),
+		},
+		"markdown: go declaration": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type: GoDeclaration,
+					GoDecl: &GoDecl{
+						Kind: token.FUNC,
+						Name: "NewGreeter",
+					},
+				},
+				Wrap: &Wrap{
+					LanguageType: "go",
+				},
+			},
+			want: []byte("```" + `go
+// NewGreeter creates a Greeter.
+func NewGreeter(name string) *Greeter {
+	if name == "" {
+		name = DefaultName
+	}
+	return &Greeter{Name: name}
+}
+` + "```" + `
+`),
+		},
+		"markdown: go declaration, method without doc comment": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type: GoDeclaration,
+					GoDecl: &GoDecl{
+						Kind:       token.FUNC,
+						Name:       "Greeter.Greet",
+						WithoutDoc: true,
+					},
+				},
+			},
+			want: []byte(`func (g *Greeter) Greet() string {
+	return "Hello, " + g.Name
+}
+`),
+		},
+		"markdown: go declaration, var block": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type: GoDeclaration,
+					GoDecl: &GoDecl{
+						Kind: token.VAR,
+						Name: "ErrInvalid",
+					},
+				},
+			},
+			want: []byte(`// Errors returned from this package.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid")
+)
+`),
+		},
+		"markdown: line range with anchors": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:           LineRange,
+					LineFromAnchor: &Anchor{Pattern: "^func NewGreeter"},
+					LineToAnchor:   &Anchor{Pattern: "^}"},
+				},
+			},
+			want: []byte(`func NewGreeter(name string) *Greeter {
+	if name == "" {
+		name = DefaultName
+	}
+	return &Greeter{Name: name}
+}
+`),
+		},
+		"markdown: line range with exclusive anchors": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:           LineRange,
+					LineFromAnchor: &Anchor{Pattern: "^type Greeter", Exclusive: true},
+					LineToAnchor:   &Anchor{Pattern: "^}", Exclusive: true},
+				},
+			},
+			want: []byte(`	Name string
+`),
+		},
+		"yaml: line range with anchor and open end": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           LineRange,
+					LineFromAnchor: &Anchor{Pattern: "i:$"},
+					LineTo:         math.MaxInt32,
+				},
+			},
+			want: []byte(`                i:
+                  j:
+                    k: {}
+`),
+		},
+		"yaml: multiple exporter markers with indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-color-svc.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type: MultipleSelectors,
+					Selectors: []ImportLogic{
+						{Type: ExporterMarker, ExporterMarker: "latest-svc"},
+						{Type: ExporterMarker, ExporterMarker: "v0.1.0"},
+					},
+				},
+				Indentation: &Indentation{
+					Mode:   AbsoluteIndentation,
+					Length: 0,
+				},
+			},
+			want: []byte(`- image: docker.io/rytswd/color-svc:latest
+  name: color-svc
+  command:
+    - color-svc
+  ports:
+    - containerPort: 8800
+- image: docker.io/rytswd/color-svc:0.1.0
+  name: color-svc
+  command:
+    - color-svc
+  ports:
+    - containerPort: 8800
+`),
+		},
+		"yaml: exporter marker excluding nested exporter marker": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "sample-nested",
+					Excludes: []ImportLogic{
+						{Type: ExporterMarker, ExporterMarker: "metadata-only"},
+					},
+				},
+				Indentation: &Indentation{
+					Mode:   AbsoluteIndentation,
+					Length: 0,
+				},
+			},
+			want: []byte(`nested:
+  more:
+    data:
+      sample: This is a sample data
+`),
+		},
+		"markdown: line range excluding lines": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/note.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   3,
+					Excludes: []ImportLogic{
+						{Type: CommaSeparatedLines, Lines: []int{2}},
+					},
+				},
+			},
+			want: []byte(`This is test data.
+🍸 Emojis 🍷 Supported 🍺
+`),
+		},
+		"yaml: dedent with extra indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "metadata-only",
+				},
+				Indentation: &Indentation{
+					Mode:   DedentIndentation,
+					Length: 4,
+				},
+			},
+			want: []byte(`    metadata:
+      name: sample-data
+      namespace: sample-namespace
+`),
+		},
+		"yaml: exporter with vars": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-template.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "service",
+				},
+				Vars: &Vars{
+					Values: map[string]string{
+						"name": "frontend",
+						"port": "8080",
+					},
+				},
+			},
+			want: []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+  labels:
+    app: frontend
+    release: "{{ .Release.Name }}"
+spec:
+  ports:
+    - port: 8080
+`),
+		},
+		"yaml: exporter with replace": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-template.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "service",
+				},
+				Indentation: &Indentation{
+					Mode:   ExtraIndentation,
+					Length: 2,
+				},
+				Replaces: []Replace{
+					{Pattern: `\{\{\s*(\w+)\s*\}\}`, Replacement: "<$1>"},
+					{Pattern: `^kind: \w+`, Replacement: "kind: Pod"},
+				},
+			},
+			want: []byte(`  apiVersion: v1
+  kind: Pod
+  metadata:
+    name: <name>
+    labels:
+      app: <name>
+      release: "{{ .Release.Name }}"
+  spec:
+    ports:
+      - port: <port>
+`),
+		},
+		"yaml: caption below with extra indentation": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-resource.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "min-resource",
+				},
+				Indentation: &Indentation{
+					Mode:   ExtraIndentation,
+					Length: 2,
+				},
+				Caption: &Caption{Position: CaptionBelow},
+			},
+			want: []byte(`    resources:
+      requests:
+        cpu: 10m
+        memory: 10Mi
+
+      limits:
+        cpu: 30m
+        memory: 30Mi
+    # Source: ../../testdata/yaml/snippet-k8s-resource.yaml#L3-L10
+`),
+		},
+		"other: squeeze blank lines": {
+			callerFile: "./some_unknown_file_type",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-blank-lines.md",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   10,
+				},
+				Squeeze: true,
+			},
+			want: []byte(`# Padded Snippet
+
+<!-- == export: padded / begin == -->
+
+First paragraph.
+
+Second paragraph.
+`),
+		},
+		"other: trim blank lines across selectors": {
+			callerFile: "./some_unknown_file_type",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/markdown/snippet-blank-lines.md",
+				},
+				ImportLogic: ImportLogic{
+					Type: MultipleSelectors,
+					Selectors: []ImportLogic{
+						{Type: LineRange, LineFrom: 4, LineTo: 7},
+						{Type: LineRange, LineFrom: 9, LineTo: 11},
+					},
+				},
+				Trim: true,
+			},
+			want: []byte(`First paragraph.
+
+
+Second paragraph.
+`),
+		},
+		"yaml: strip comments": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-comments.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   9,
+				},
+				Strip: &Strip{},
+			},
+			want: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sample # internal note
+  annotations:
+    link: "https://example.com/#section" # trailing
+    tag: app#v1
+`),
+		},
+		"yaml: strip trailing comments": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-with-comments.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   9,
+				},
+				Strip: &Strip{Trailing: true},
+			},
+			want: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sample
+  annotations:
+    link: "https://example.com/#section"
+    tag: app#v1
+`),
+		},
+		"markdown: strip go comments": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:   GoDeclaration,
+					GoDecl: &GoDecl{Kind: token.VAR, Name: "ErrNotFound"},
+				},
+				Strip: &Strip{},
+			},
+			want: []byte(`var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid")
+)
+`),
+		},
+		"yaml: convert json to yaml": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/openapi.json",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 5,
+					LineTo:   12,
+				},
+				Indentation: &Indentation{
+					Mode:   ExtraIndentation,
+					Length: 4,
+				},
+				Convert: &Convert{Format: ConvertYAML},
+			},
+			want: []byte(`    Pet:
+      type: object
+      required:
+        - id
+        - name
+      properties:
+        id:
+          type: integer
+          format: int64
+        name:
+          type: string
+`),
+		},
+		"yaml: convert json to yaml aligned with marker": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/openapi.json",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 5,
+					LineTo:   12,
+				},
+				Indentation: &Indentation{
+					Mode:              AlignIndentation,
+					MarkerIndentation: 4,
+				},
+				Convert: &Convert{Format: ConvertYAML},
+			},
+			want: []byte(`    Pet:
+      type: object
+      required:
+        - id
+        - name
+      properties:
+        id:
+          type: integer
+          format: int64
+        name:
+          type: string
+`),
+		},
+		"yaml: convert invalid json": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/openapi.json",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 5,
+					LineTo:   10,
+				},
+				Convert: &Convert{Format: ConvertYAML},
+			},
+			wantErr: ErrConvertFormat,
+		},
+		"yaml: comment style": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 5,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-resource.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type:           ExporterMarker,
+					ExporterMarker: "min-resource",
+				},
+				Indentation: &Indentation{
+					Mode:   ExtraIndentation,
+					Length: 2,
+				},
+				ImportStyle: &ImportStyle{
+					Mode: Comment,
+				},
+			},
+			want: []byte(`    # resources:
+    #   requests:
+    #     cpu: 10m
+    #     memory: 10Mi
+    #
+    #   limits:
+    #     cpu: 30m
+    #     memory: 30Mi
+`),
+		},
+		"yaml: block scalar": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 3,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/entrypoint.sh",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
+				},
+				BlockScalar: &BlockScalar{Key: "entrypoint.sh", Indentation: 2},
+			},
+			want: []byte(`  entrypoint.sh: |
+    #!/bin/sh
+    set -eu
 
+    echo "starting"
+    exec "$@"
 `),
 		},
-		"markdown: exporter marker with quote": {
-			callerFile: "./some_file.md",
+		"yaml: block scalar with leading indentation": {
+			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 1,
+				LineToInsertAt: 3,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/markdown/snippet-with-exporter.md",
+					File: "../../testdata/go/snippet-declarations.go",
 				},
 				ImportLogic: ImportLogic{
-					Type:           ExporterMarker,
-					ExporterMarker: "test_exporter",
-				},
-				ImportStyle: &ImportStyle{
-					Mode: Quote,
+					Type:     LineRange,
+					LineFrom: 21,
+					LineTo:   25,
 				},
+				BlockScalar: &BlockScalar{Key: "main.go"},
 			},
-			want: []byte(`> 
-> ✨✨✨✨✨✨✨✨
-> ✨✨✨✨✨✨✨✨
-> ✨✨✨✨✨✨✨✨
-> 🚀🚀🚀🚀🚀🚀🚀🚀
-> ✨✨✨✨✨✨✨✨
-> ✨✨✨✨✨✨✨✨
-> ✨✨✨✨✨✨✨✨
-> 
-`),
+			want: []byte("main.go: |2\n  \tif name == \"\" {\n  \t\tname = DefaultName\n  \t}\n  \treturn &Greeter{Name: name}\n  }\n"),
 		},
-		"markdown: exporter marker with verbatim": {
-			callerFile: "./some_file.md",
+		"yaml: max lines with default indicator": {
+			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 1,
+				LineToInsertAt: 5,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/markdown/snippet-with-exporter.md",
+					File: "../../testdata/yaml/snippet-k8s-resource.yaml",
 				},
 				ImportLogic: ImportLogic{
 					Type:           ExporterMarker,
-					ExporterMarker: "test_exporter",
+					ExporterMarker: "min-resource",
 				},
-				Wrap: &Wrap{
-					LanguageType: "some-lang",
+				Indentation: &Indentation{
+					Mode:   AbsoluteIndentation,
+					Length: 4,
 				},
+				MaxLines: &MaxLines{Count: 3},
 			},
-			want: []byte("```" + `some-lang
-
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
-🚀🚀🚀🚀🚀🚀🚀🚀
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
-✨✨✨✨✨✨✨✨
-
-` + "```" + `
+			want: []byte(`    resources:
+      requests:
+        cpu: 10m
+        # ...
 `),
 		},
-		"yaml: line range": {
+		"yaml: max lines with block scalar": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 3,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+					File: "../../testdata/other/entrypoint.sh",
 				},
 				ImportLogic: ImportLogic{
 					Type:     LineRange,
-					LineFrom: 2,
-					LineTo:   5,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
 				},
-				Indentation: nil,
+				Wrap:        &Wrap{LanguageType: "literal"},
+				BlockScalar: &BlockScalar{Key: "entrypoint.sh", Indentation: 2},
+				MaxLines:    &MaxLines{Count: 3},
 			},
-			want: []byte(`  b:
-    c:
-      d:
-        e:
+			want: []byte(`  entrypoint.sh: |
+    #!/bin/sh
+    set -eu
+
+  # ...
 `),
 		},
-		"yaml: line range with URL, github.com - this may fail if GitHub is down": {
+		"yaml: max lines with block scalar and custom indicator": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 3,
 				ImportTargetFile: ImportTargetFile{
-					Type: URLBased,
-					File: "https://github.com/upsidr/importer/blob/main/testdata/yaml/snippet-simple-tree.yaml",
+					Type: PathBased,
+					File: "../../testdata/other/entrypoint.sh",
 				},
 				ImportLogic: ImportLogic{
 					Type:     LineRange,
-					LineFrom: 2,
-					LineTo:   5,
+					LineFrom: 1,
+					LineTo:   math.MaxInt32,
 				},
-				Indentation: nil,
+				BlockScalar: &BlockScalar{Key: "entrypoint.sh"},
+				MaxLines:    &MaxLines{Count: 2, Indicator: "(truncated)"},
 			},
-			want: []byte(`  b:
-    c:
-      d:
-        e:
+			want: []byte(`entrypoint.sh: |
+  #!/bin/sh
+  set -eu
+# (truncated)
 `),
 		},
-		"yaml: line range with URL, raw.githubusercontent.com": {
+		"yaml: max lines with link": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
 				LineToInsertAt: 5,
 				ImportTargetFile: ImportTargetFile{
-					Type: URLBased,
-					File: "https://raw.githubusercontent.com/upsidr/importer/main/testdata/yaml/snippet-simple-tree.yaml",
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-k8s-resource.yaml",
 				},
 				ImportLogic: ImportLogic{
-					Type:     LineRange,
-					LineFrom: 2,
-					LineTo:   5,
+					Type:           ExporterMarker,
+					ExporterMarker: "min-resource",
 				},
-				Indentation: nil,
+				MaxLines: &MaxLines{Count: 2, Link: true},
 			},
-			want: []byte(`  b:
-    c:
-      d:
-        e:
+			want: []byte(`  resources:
+    requests:
+  # See full file: ../../testdata/yaml/snippet-k8s-resource.yaml#L3-L10
 `),
 		},
-		"yaml: comma separated lines": {
-			callerFile: "./some_file.yaml",
+		"other: max lines not exceeded": {
+			callerFile: "./some_unknown_file_type",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 1,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
 					File: "../../testdata/yaml/snippet-simple-tree.yaml",
 				},
 				ImportLogic: ImportLogic{
-					Type:  LineRange,
-					Lines: []int{1, 2, 4},
+					Type:     LineRange,
+					LineFrom: 1,
+					LineTo:   2,
 				},
-				Indentation: nil,
+				MaxLines: &MaxLines{Count: 2},
 			},
 			want: []byte(`a:
   b:
-      d:
 `),
 		},
-		"yaml: exporter marker": {
+		"yaml: exporter from shell script": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 1,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+					File: "../../testdata/other/with-exporter.sh",
 				},
 				ImportLogic: ImportLogic{
 					Type:           ExporterMarker,
-					ExporterMarker: "long-tree",
+					ExporterMarker: "env",
 				},
-				Indentation: nil,
 			},
-			want: []byte(`a:
-  b:
-    c:
-      d:
-        e:
-          f:
-            g:
-              h:
-                i:
-                  j:
-                    k: {}
+			want: []byte(`export APP_ENV=production
 `),
 		},
-		"yaml: exporter marker with absolute indentation": {
+		"yaml: line range keeps exporter marker lines": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 1,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+					File: "../../testdata/other/with-exporter.sh",
 				},
 				ImportLogic: ImportLogic{
-					Type:           ExporterMarker,
-					ExporterMarker: "metadata-only",
+					Type:     LineRange,
+					LineFrom: 2,
+					LineTo:   4,
+				},
+			},
+			want: []byte(`# == export: env / begin ==
+export APP_ENV=production
+# == export: env / end ==
+`),
+		},
+		"yaml: extra indentation with tab indented target": {
+			callerFile: "./some_file.yaml",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:     LineRange,
+					LineFrom: 15,
+					LineTo:   17,
 				},
 				Indentation: &Indentation{
-					Mode:   AbsoluteIndentation,
-					Length: 30,
+					Mode:   ExtraIndentation,
+					Length: 4,
 				},
 			},
-			want: []byte(`                              metadata:
-                                name: sample-data
-                                namespace: sample-namespace
+			want: []byte(`    type Greeter struct {
+        Name string
+    }
 `),
 		},
-		"yaml: exporter marker with absolute indentation, with zero indentation": {
+		"yaml: align with tab indented target": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 1,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+					File: "../../testdata/go/snippet-declarations.go",
 				},
 				ImportLogic: ImportLogic{
-					Type:           ExporterMarker,
-					ExporterMarker: "metadata-only",
+					Type:     LineRange,
+					LineFrom: 21,
+					LineTo:   24,
 				},
 				Indentation: &Indentation{
-					Mode:   AbsoluteIndentation,
-					Length: 0,
+					Mode:              AlignIndentation,
+					MarkerIndentation: 2,
 				},
 			},
-			want: []byte(`metadata:
-  name: sample-data
-  namespace: sample-namespace
+			want: []byte(`  if name == "" {
+      name = DefaultName
+  }
+  return &Greeter{Name: name}
 `),
 		},
-		"yaml: exporter marker with extra indentation": {
+		"yaml: absolute indentation in spaces with tab indented exporter": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 1,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+					File: "../../testdata/other/Makefile",
 				},
 				ImportLogic: ImportLogic{
 					Type:           ExporterMarker,
-					ExporterMarker: "sample-nested",
+					ExporterMarker: "build-steps",
 				},
 				Indentation: &Indentation{
-					Mode:   ExtraIndentation,
+					Mode:   AbsoluteIndentation,
 					Length: 2,
+					Char:   SpaceIndent,
 				},
 			},
-			want: []byte(`    nested:
-      more:
-        data:
-          sample: This is a sample data
-        metadata:
-          name: sample-data
-          namespace: sample-namespace
+			want: []byte(`  go build ./...
+  go vet ./...
 `),
 		},
-		"yaml: exporter marker with align indentation": {
-			callerFile: "./some_file.yaml",
+		"other: dedent": {
+			callerFile: "./some_unknown_file_type",
 			marker: &Marker{
-				LineToInsertAt: 5,
+				LineToInsertAt: 1,
 				ImportTargetFile: ImportTargetFile{
 					Type: PathBased,
-					File: "../../testdata/yaml/snippet-with-exporter.yaml",
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
 				},
 				ImportLogic: ImportLogic{
-					Type:           ExporterMarker,
-					ExporterMarker: "sample-nested",
+					Type:     LineRange,
+					LineFrom: 9,
+					LineTo:   11,
 				},
 				Indentation: &Indentation{
-					Mode:              AlignIndentation,
-					MarkerIndentation: 10,
+					Mode: DedentIndentation,
 				},
 			},
-			want: []byte(`          nested:
-            more:
-              data:
-                sample: This is a sample data
-              metadata:
-                name: sample-data
-                namespace: sample-namespace
+			want: []byte(`i:
+  j:
+    k: {}
+`),
+		},
+		"other: dedent with separator": {
+			callerFile: "./some_unknown_file_type",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/yaml/snippet-simple-tree.yaml",
+				},
+				ImportLogic: ImportLogic{
+					Type: MultipleSelectors,
+					Selectors: []ImportLogic{
+						{Type: CommaSeparatedLines, Lines: []int{9}},
+						{Type: LineRange, LineFrom: 10, LineTo: 11},
+					},
+				},
+				Separator: &Separator{
+					Line: "--",
+				},
+				Indentation: &Indentation{
+					Mode: DedentIndentation,
+				},
+			},
+			want: []byte(`i:
+--
+  j:
+    k: {}
 `),
 		},
 		"other: range process": {
@@ -456,6 +2236,58 @@ func TestProcessSingleMarker(t *testing.T) {
 			},
 			wantErr: ErrNonSuccessCode,
 		},
+		"go declaration not found": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type: GoDeclaration,
+					GoDecl: &GoDecl{
+						Kind: token.TYPE,
+						Name: "NewGreeter", // This is func, not type
+					},
+				},
+			},
+			wantErr: ErrNoMatchingDeclaration,
+		},
+		"go declaration with non-Go file": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/other/note.txt",
+				},
+				ImportLogic: ImportLogic{
+					Type: GoDeclaration,
+					GoDecl: &GoDecl{
+						Kind: token.FUNC,
+						Name: "main",
+					},
+				},
+			},
+			wantErr: ErrInvalidGoSource,
+		},
+		"anchor not found": {
+			callerFile: "./some_file.md",
+			marker: &Marker{
+				LineToInsertAt: 1,
+				ImportTargetFile: ImportTargetFile{
+					Type: PathBased,
+					File: "../../testdata/go/snippet-declarations.go",
+				},
+				ImportLogic: ImportLogic{
+					Type:           LineRange,
+					LineFromAnchor: &Anchor{Pattern: "^func main"},
+					LineTo:         math.MaxInt32,
+				},
+			},
+			wantErr: ErrNoMatchingAnchor,
+		},
 		"invalid address": {
 			callerFile: "./some_file.yaml",
 			marker: &Marker{
@@ -543,3 +2375,64 @@ func TestPreprocessURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCaptionSource(t *testing.T) {
+	sections := []section{
+		{lines: []line{{5, "a"}, {6, "b"}}},
+		{lines: []line{{0, "---"}}},
+		{lines: []line{{9, "c"}}},
+	}
+
+	cases := map[string]struct {
+		target   ImportTargetFile
+		sections []section
+
+		wantLabel string
+		wantLink  string
+	}{
+		"local file": {
+			target:    ImportTargetFile{Type: PathBased, File: "../src/marker.go"},
+			sections:  sections,
+			wantLabel: "marker.go#L5-L9",
+			wantLink:  "../src/marker.go#L5-L9",
+		},
+		"local file with single line": {
+			target:    ImportTargetFile{Type: PathBased, File: "./note.txt"},
+			sections:  []section{{lines: []line{{3, "a"}}}},
+			wantLabel: "note.txt#L3",
+			wantLink:  "./note.txt#L3",
+		},
+		"github.com": {
+			target:    ImportTargetFile{Type: URLBased, File: "https://github.com/upsidr/importer/blob/main/README.md"},
+			sections:  sections,
+			wantLabel: "README.md#L5-L9",
+			wantLink:  "https://github.com/upsidr/importer/blob/main/README.md#L5-L9",
+		},
+		"raw.githubusercontent.com": {
+			target:    ImportTargetFile{Type: URLBased, File: "https://raw.githubusercontent.com/upsidr/importer/main/testdata/yaml/snippet-simple-tree.yaml"},
+			sections:  sections,
+			wantLabel: "snippet-simple-tree.yaml#L5-L9",
+			wantLink:  "https://github.com/upsidr/importer/blob/main/testdata/yaml/snippet-simple-tree.yaml#L5-L9",
+		},
+		"non-github.com": {
+			target:    ImportTargetFile{Type: URLBased, File: "https://example.com/files/setup.sh?version=2"},
+			sections:  sections,
+			wantLabel: "setup.sh",
+			wantLink:  "https://example.com/files/setup.sh",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &Marker{ImportTargetFile: tc.target}
+			label, link := m.captionSource(tc.sections)
+
+			if diff := cmp.Diff(tc.wantLabel, label); diff != "" {
+				t.Errorf("label didn't match (-want / +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantLink, link); diff != "" {
+				t.Errorf("link didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package marker
+
+import "regexp"
+
+// replaceContent applies search and replace on each line, in the order of
+// the replace options.
+func replaceContent(sections []section, replaces []Replace) []section {
+	res := make([]*regexp.Regexp, 0, len(replaces))
+	for _, r := range replaces {
+		res = append(res, regexp.MustCompile(r.Pattern)) // Pattern is validated by NewMarker
+	}
+
+	result := make([]section, 0, len(sections))
+	for _, s := range sections {
+		lines := make([]line, 0, len(s.lines))
+		for _, l := range s.lines {
+			text := l.text
+			for i, re := range res {
+				text = re.ReplaceAllString(text, replaces[i].Replacement)
+			}
+			lines = append(lines, line{number: l.number, text: text})
+		}
+		s.lines = lines
+		result = append(result, s)
+	}
+	return result
+}
@@ -0,0 +1,194 @@
+package marker
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// section is a block of lines selected from the import target. When multiple
+// selectors are used, each selector results in a separate section.
+type section struct {
+	lines []line
+
+	// fromExporter is set when the lines are selected using Exporter Marker.
+	// exporterIndentation holds the preceding whitespaces of the Exporter
+	// Marker, which is used for YAML indentation adjustment.
+	fromExporter        bool
+	exporterIndentation string
+}
+
+// line holds a single line from the import target, along with its original
+// line number. Line number of 0 is used for lines not found in the import
+// target, such as separator.
+type line struct {
+	number int
+	text   string
+}
+
+// selectSections reads the import target content, and selects the lines
+// based on the import logic. When multiple selectors are used, sections are
+// returned in the order of selectors, with separator in between if specified.
+// Lines matching any of the exclusions are removed from the sections.
+//
+// `fileType` is the extension of the importing file, which is used for
+// finding the Exporter Marker syntax.
+func (m *Marker) selectSections(content []byte, fileType string) ([]section, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	exporter := exporterSyntax{
+		re: regexp.MustCompile(exporterMarkerFor(fileType, m.ImportTargetFile.File)),
+
+		// Only Markdown drops Exporter Marker lines found in line range.
+		keepInRange: fileType != ".md",
+	}
+
+	excluded := map[int]bool{}
+	for _, e := range m.ImportLogic.Excludes {
+		ss, err := selectLogic(content, lines, e, exporter)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range ss {
+			for _, l := range s.lines {
+				excluded[l.number] = true
+			}
+		}
+	}
+
+	selected, err := selectLogic(content, lines, m.ImportLogic, exporter)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []section{}
+	for i, s := range selected {
+		if i > 0 && m.Separator != nil {
+			sections = append(sections, section{lines: []line{{text: m.Separator.Line}}})
+		}
+
+		if len(excluded) > 0 {
+			kept := []line{}
+			for _, l := range s.lines {
+				if !excluded[l.number] {
+					kept = append(kept, l)
+				}
+			}
+			s.lines = kept
+		}
+		sections = append(sections, s)
+	}
+
+	if fileType == ".md" && m.ImportStyle != nil && m.ImportStyle.Mode == Table {
+		sections = withTableHeader(sections, lines)
+	}
+
+	return sections, nil
+}
+
+// selectLogic returns a section for each selector in the import logic.
+func selectLogic(content []byte, lines []string, logic ImportLogic, exporter exporterSyntax) ([]section, error) {
+	logics := []ImportLogic{logic}
+	if logic.Type == MultipleSelectors {
+		logics = logic.Selectors
+	}
+
+	sections := []section{}
+	for _, l := range logics {
+		// Some import logic can only be determined with the actual file
+		// content. Convert those into line range before processing.
+		resolved, err := l.resolve(content)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, selectSection(lines, resolved, exporter))
+	}
+	return sections, nil
+}
+
+// exporterSyntax holds how Exporter Marker is handled for the import.
+type exporterSyntax struct {
+	re *regexp.Regexp
+
+	// keepInRange keeps Exporter Marker lines when they are selected with
+	// line range or line numbers.
+	keepInRange bool
+}
+
+// selectSection selects lines matching any of line range, line numbers, or
+// Exporter Marker in the import logic. Exporter Marker lines themselves are
+// not selected, unless they are selected with line numbers and the syntax
+// keeps them.
+func selectSection(lines []string, logic ImportLogic, exporter exporterSyntax) section {
+	s := section{}
+	exporterRe := exporter.re
+
+	withinExportMarker := false
+	for i, text := range lines {
+		currentLine := i + 1
+
+		if ms := exporterRe.FindStringSubmatch(text); ms != nil {
+			if exporter.keepInRange && isLineSelected(currentLine, logic) {
+				s.lines = append(s.lines, line{number: currentLine, text: text})
+			}
+
+			name := ms[exporterRe.SubexpIndex("export_marker_name")]
+			if logic.ExporterMarker == "" || name != logic.ExporterMarker {
+				continue
+			}
+
+			switch ms[exporterRe.SubexpIndex("exporter_marker_condition")] {
+			case "begin":
+				withinExportMarker = true
+				s.fromExporter = true
+				if idx := exporterRe.SubexpIndex("export_marker_indent"); idx >= 0 {
+					x := ms[idx]
+					s.exporterIndentation = x[:len(x)-len(strings.TrimLeft(x, " \t"))]
+				}
+			case "end":
+				withinExportMarker = false
+			}
+			continue
+		}
+
+		if withinExportMarker || isLineSelected(currentLine, logic) {
+			s.lines = append(s.lines, line{number: currentLine, text: text})
+		}
+	}
+
+	return s
+}
+
+func isLineSelected(currentLine int, logic ImportLogic) bool {
+	if currentLine >= logic.LineFrom && currentLine <= logic.LineTo {
+		return true
+	}
+	for _, l := range logic.Lines {
+		if currentLine == l {
+			return true
+		}
+	}
+	return false
+}
+
+// exporterMarkerFor returns the Exporter Marker syntax. YAML importing file
+// always uses YAML syntax regardless of the import target, and otherwise the
+// syntax is based on the import target file extension.
+func exporterMarkerFor(fileType, targetFile string) string {
+	if fileType == ".yaml" || fileType == ".yml" {
+		return ExporterMarkerYAML
+	}
+
+	switch filepath.Ext(targetFile) {
+	case ".yaml", ".yml":
+		return ExporterMarkerYAML
+	default:
+		return ExporterMarkerMarkdown
+	}
+}
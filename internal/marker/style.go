@@ -0,0 +1,176 @@
+package marker
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// applyListStyle converts each non-empty line into Markdown list item. Empty
+// lines are removed. Deeper indentation in the original line is treated as
+// nested list item.
+func applyListStyle(lines []line, mode StyleMode) []line {
+	result := []line{}
+
+	indents := []int{}  // Original indentation for each nest level
+	counters := []int{} // Ordered list numbering for each nest level
+	for _, l := range lines {
+		trimmed := strings.TrimLeft(l.text, " \t")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+
+		indent := len(l.text) - len(trimmed)
+		for len(indents) > 0 && indent < indents[len(indents)-1] {
+			indents = indents[:len(indents)-1]
+			counters = counters[:len(counters)-1]
+		}
+		if len(indents) == 0 || indent > indents[len(indents)-1] {
+			indents = append(indents, indent)
+			counters = append(counters, 0)
+		}
+		level := len(indents) - 1
+		counters[level]++
+
+		var item string
+		switch mode {
+		case UnorderedList:
+			item = strings.Repeat("  ", level) + "- " + trimmed
+		case OrderedList:
+			item = strings.Repeat("    ", level) + strconv.Itoa(counters[level]) + ". " + trimmed
+		case TaskList:
+			item = strings.Repeat("  ", level) + "- [ ] " + trimmed
+		}
+		result = append(result, line{number: l.number, text: item})
+	}
+
+	return result
+}
+
+// withTableHeader puts the first line of the import target at the beginning
+// of sections as the table header, so that line selection only applies to the
+// data rows. When the first line is also selected, it is not repeated.
+func withTableHeader(sections []section, lines []string) []section {
+	if len(lines) == 0 {
+		return sections
+	}
+
+	result := []section{{lines: []line{{number: 1, text: lines[0]}}}}
+	for _, s := range sections {
+		kept := make([]line, 0, len(s.lines))
+		for _, l := range s.lines {
+			if l.number != 1 {
+				kept = append(kept, l)
+			}
+		}
+		s.lines = kept
+		result = append(result, s)
+	}
+	return result
+}
+
+// applyTableStyle converts CSV lines into Markdown table, using the first line
+// as the header row. TSV is used instead of CSV when the import target has
+// ".tsv" extension. Empty lines are removed, and columns are padded so that
+// the table is aligned.
+func applyTableStyle(lines []line, targetFile string) ([]line, error) {
+	comma := ','
+	if strings.EqualFold(filepath.Ext(targetFile), ".tsv") {
+		comma = '\t'
+	}
+
+	rows := [][]string{}
+	numbers := []int{}
+	columns := 0
+	for _, l := range lines {
+		if strings.TrimSpace(l.text) == "" {
+			continue
+		}
+
+		r := csv.NewReader(strings.NewReader(l.text))
+		r.Comma = comma
+		r.LazyQuotes = true
+		record, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("%w at line %d, %v", ErrInvalidTableData, l.number, err)
+		}
+		for i, cell := range record {
+			record[i] = strings.ReplaceAll(strings.TrimSpace(cell), "|", `\|`)
+		}
+
+		rows = append(rows, record)
+		numbers = append(numbers, l.number)
+		if len(record) > columns {
+			columns = len(record)
+		}
+	}
+	if len(rows) == 0 {
+		return []line{}, nil
+	}
+
+	widths := make([]int, columns)
+	for i := range widths {
+		widths[i] = 3 // Minimum for alignment row
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	formatRow := func(cells []string) string {
+		b := strings.Builder{}
+		b.WriteString("|")
+		for i, w := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			b.WriteString(" " + cell + strings.Repeat(" ", w-utf8.RuneCountInString(cell)) + " |")
+		}
+		return b.String()
+	}
+
+	alignment := make([]string, columns)
+	for i, w := range widths {
+		alignment[i] = strings.Repeat("-", w)
+	}
+
+	result := []line{{number: numbers[0], text: formatRow(rows[0])}}
+	result = append(result, line{text: formatRow(alignment)})
+	for i, row := range rows[1:] {
+		result = append(result, line{number: numbers[i+1], text: formatRow(row)})
+	}
+	return result, nil
+}
+
+// escapeHTML escapes the characters with special meaning in HTML, so that the
+// lines can be placed within HTML tags such as <pre>.
+func escapeHTML(lines []line) []line {
+	result := make([]line, 0, len(lines))
+	for _, l := range lines {
+		result = append(result, line{number: l.number, text: html.EscapeString(l.text)})
+	}
+	return result
+}
+
+// escapeMarkdownTable converts the lines into a single line which can be
+// placed in a Markdown table cell. Pipes are escaped, and line breaks are
+// replaced with "<br>".
+func escapeMarkdownTable(lines []line) []line {
+	if len(lines) == 0 {
+		return lines
+	}
+
+	texts := make([]string, 0, len(lines))
+	for _, l := range lines {
+		texts = append(texts, strings.ReplaceAll(l.text, "|", `\|`))
+	}
+	return []line{{number: lines[0].number, text: strings.Join(texts, "<br>")}}
+}
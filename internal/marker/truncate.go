@@ -0,0 +1,91 @@
+package marker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// truncateSections keeps the first lines up to the max lines, and returns
+// whether the lines were truncated. Unless the link or block scalar is used,
+// truncation indicator is added with the same indentation as the last line
+// kept.
+func (m *Marker) truncateSections(sections []section, fileType string) ([]section, bool) {
+	result := []section{}
+	remaining := m.MaxLines.Count
+	truncated := false
+	for _, s := range sections {
+		if remaining == 0 {
+			truncated = truncated || len(s.lines) > 0
+			continue
+		}
+		if len(s.lines) > remaining {
+			s.lines = s.lines[:remaining:remaining]
+			truncated = true
+		}
+		remaining -= len(s.lines)
+		result = append(result, s)
+	}
+	if !truncated || m.MaxLines.Link || m.embedsBlockScalar(fileType) {
+		return result, truncated
+	}
+
+	indicator := m.truncationIndicator(fileType)
+	last := &result[len(result)-1]
+	indent := ""
+	if n := len(last.lines); n > 0 {
+		text := last.lines[n-1].text
+		indent = text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+	}
+	last.lines = append(last.lines, line{text: indent + indicator})
+
+	return result, true
+}
+
+// truncationIndicator returns the indicator for the truncated lines. The
+// default indicator is "...", or "# ..." for YAML.
+func (m *Marker) truncationIndicator(fileType string) string {
+	if m.MaxLines.Indicator != "" {
+		return m.MaxLines.Indicator
+	}
+	if fileType == ".yaml" || fileType == ".yml" {
+		return "# ..."
+	}
+	return "..."
+}
+
+// embedsBlockScalar checks whether the imported data is embedded as YAML
+// block scalar.
+func (m *Marker) embedsBlockScalar(fileType string) bool {
+	return m.BlockScalar != nil && (fileType == ".yaml" || fileType == ".yml")
+}
+
+// addBlockScalarIndicator adds the truncation indicator after the block
+// scalar, as a comment with the same indentation as the key. Indicator within
+// the block scalar would become a part of the embedded data.
+func (m *Marker) addBlockScalarIndicator(data []byte) []byte {
+	indicator := m.truncationIndicator(".yaml")
+	if !strings.HasPrefix(indicator, "#") {
+		indicator = "# " + indicator
+	}
+	data = append(data, []byte(strings.Repeat(" ", m.BlockScalar.Indentation)+indicator)...)
+	return append(data, br)
+}
+
+// addFullFileLink adds a link to the import target after the processed data,
+// based on the file type.
+func (m *Marker) addFullFileLink(data []byte, sections []section, fileType string) []byte {
+	_, link := m.captionSource(sections)
+
+	switch fileType {
+	case ".md":
+		data = append(data, br)
+		data = append(data, []byte(fmt.Sprintf("[See full file](%s)", link))...)
+	case ".yaml", ".yml":
+		indent := data[:len(data)-len(bytes.TrimLeft(data, " \t"))]
+		data = append(data, []byte(fmt.Sprintf("%s# See full file: %s", indent, link))...)
+	default:
+		data = append(data, []byte("See full file: "+link)...)
+	}
+	return append(data, br)
+}
@@ -0,0 +1,37 @@
+package marker
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	varName        = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	varPlaceholder = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_-]*)\s*\}\}`)
+)
+
+// substituteVars replaces placeholders such as "{{name}}" with the variable
+// values. Placeholders without matching variable are left untouched, so that
+// other template syntax such as Helm's "{{ .Values.name }}" is kept as is.
+func substituteVars(sections []section, v *Vars) []section {
+	result := make([]section, 0, len(sections))
+	for _, s := range sections {
+		lines := make([]line, 0, len(s.lines))
+		for _, l := range s.lines {
+			text := l.text
+			if strings.Contains(text, "{{") {
+				text = varPlaceholder.ReplaceAllStringFunc(text, func(p string) string {
+					name := varPlaceholder.FindStringSubmatch(p)[1]
+					if value, found := v.Values[name]; found {
+						return value
+					}
+					return p
+				})
+			}
+			lines = append(lines, line{number: l.number, text: text})
+		}
+		s.lines = lines
+		result = append(result, s)
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package snippet
+
+import "errors"
+
+// Errors returned from this package.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid")
+)
+
+// DefaultName is used when no name is given.
+const DefaultName = "importer"
+
+// Greeter says hello.
+type Greeter struct {
+	Name string
+}
+
+// NewGreeter creates a Greeter.
+func NewGreeter(name string) *Greeter {
+	if name == "" {
+		name = DefaultName
+	}
+	return &Greeter{Name: name}
+}
+
+// Greet returns the greeting message.
+func (g *Greeter) Greet() string {
+	return "Hello, " + g.Name
+}
@@ -0,0 +1,5 @@
+#!/bin/sh
+set -eu
+
+echo "starting"
+exec "$@"
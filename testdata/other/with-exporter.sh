@@ -0,0 +1,5 @@
+#!/bin/sh
+# == export: env / begin ==
+export APP_ENV=production
+# == export: env / end ==
+echo "done"